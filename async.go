@@ -0,0 +1,104 @@
+package jsonrpc2
+
+import (
+	"context"
+	"sync"
+)
+
+// Call is a request sent by Go or Async which response is awaited in the background.
+type Call[TResult Result] struct {
+	Id     interface{}
+	Method string
+
+	done     chan struct{}
+	cancel   context.CancelFunc
+	response *Response[TResult]
+	err      error
+}
+
+// Go sends request without waiting for the response. On StreamEndpoint Go returns once the request
+// is written, so requests sent from one goroutine are written in order. The request is cancelled
+// when ctx is done or Cancel is called.
+func Go[TParams Params, TResult Result](ctx context.Context, c EndpointClient, method string, params TParams) *Call[TResult] {
+	return Async[TParams, TResult](ctx, c, method, params, nil)
+}
+
+// Async is like Go, callback is called from the background goroutine when the call is done.
+func Async[TParams Params, TResult Result](ctx context.Context, c EndpointClient, method string, params TParams, callback func(*Call[TResult])) *Call[TResult] {
+	call := &Call[TResult]{Method: method, done: make(chan struct{})}
+	if c == nil {
+		call.finish(nil, ErrInvalidEndpoint, callback)
+		return call
+	}
+	id, err := newRequestId(c)
+	if err != nil {
+		call.finish(nil, err, callback)
+		return call
+	}
+	call.Id = id
+	if c.IsClosed() {
+		call.finish(nil, ErrStreamClosed, callback)
+		return call
+	}
+
+	written := make(chan struct{})
+	var writtenOnce sync.Once
+	ctx, call.cancel = context.WithCancel(ctx)
+	ctx = context.WithValue(ctx, writtenNotifyContextKey, func() {
+		writtenOnce.Do(func() { close(written) })
+	})
+	go func() {
+		defer call.cancel()
+		responses, err := invokeClientCalls(ctx, c, false, []*ClientCall{{Id: call.Id, Method: method, Params: params}})
+		if err == nil && len(responses) == 0 {
+			err = ErrEmptyResponse
+		}
+		if err != nil {
+			call.finish(nil, err, callback)
+			return
+		}
+		response, err := decodeResponse[TResult](responses[0])
+		call.finish(response, err, callback)
+	}()
+
+	switch c.(type) {
+	case *StreamEndpoint, *ReconnectingClient:
+		select {
+		case <-written:
+		case <-call.done:
+		}
+	}
+	return call
+}
+
+func (call *Call[TResult]) finish(response *Response[TResult], err error, callback func(*Call[TResult])) {
+	call.response, call.err = response, err
+	close(call.done)
+	if callback != nil {
+		callback(call)
+	}
+}
+
+// Done returns channel closed when the response is received or the call fails.
+func (call *Call[TResult]) Done() <-chan struct{} {
+	return call.done
+}
+
+// Wait waits for the call to finish and returns its response. If ctx is done first, ctx error
+// is returned and the call keeps running.
+func (call *Call[TResult]) Wait(ctx context.Context) (*Response[TResult], error) {
+	select {
+	case <-call.done:
+		return call.response, call.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Cancel stops waiting for the response. The call finishes with context.Canceled unless
+// it is already done, peer is notified if request cancellation is enabled on the endpoint.
+func (call *Call[TResult]) Cancel() {
+	if call.cancel != nil {
+		call.cancel()
+	}
+}
@@ -0,0 +1,94 @@
+package jsonrpc2
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamGo(t *testing.T) {
+	assert := assert.New(t)
+	connA, connB := net.Pipe()
+	s := NewStreamEndpoint(context.Background(), NewPlainObjectStream(connA))
+	c := NewStreamEndpoint(context.Background(), NewPlainObjectStream(connB))
+	release := make(chan struct{})
+	RegisterEndpointMethod(s, "double", func(ctx context.Context, n int) (int, *Error) {
+		<-release
+		return n * 2, nil
+	})
+
+	calls := make([]*Call[int], 0, 10)
+	for i := 0; i < 10; i++ {
+		calls = append(calls, Go[[]int, int](context.Background(), c, "double", []int{i + 1}))
+	}
+	for _, call := range calls {
+		select {
+		case <-call.Done():
+			t.Fatal("call finished before handler returned")
+		default:
+		}
+	}
+	close(release)
+	for i, call := range calls {
+		response, err := call.Wait(context.Background())
+		assert.Nil(err)
+		result, err := response.Unwrap()
+		assert.Nil(err)
+		assert.Equal((i+1)*2, result)
+	}
+}
+
+func TestStreamAsync(t *testing.T) {
+	assert := assert.New(t)
+	connA, connB := net.Pipe()
+	s := NewStreamEndpoint(context.Background(), NewPlainObjectStream(connA))
+	c := NewStreamEndpoint(context.Background(), NewPlainObjectStream(connB))
+	RegisterEndpointMethod(s, "hello", func(ctx context.Context, name string) (string, *Error) {
+		return "Hello " + name, nil
+	})
+
+	results := make(chan string, 1)
+	Async(context.Background(), c, "hello", []string{"World"}, func(call *Call[string]) {
+		response, err := call.Wait(context.Background())
+		assert.Nil(err)
+		results <- response.Result
+	})
+	assert.Equal("Hello World", <-results)
+
+	call := Go[[]string, string](context.Background(), nil, "hello", []string{"World"})
+	_, err := call.Wait(context.Background())
+	assert.ErrorIs(err, ErrInvalidEndpoint)
+}
+
+func TestStreamGoCancel(t *testing.T) {
+	assert := assert.New(t)
+	connA, connB := net.Pipe()
+	s := NewStreamEndpoint(context.Background(), NewPlainObjectStream(connA))
+	s.UseRequestCancellation("")
+	c := NewStreamEndpoint(context.Background(), NewPlainObjectStream(connB))
+	c.UseRequestCancellation("")
+	cancelled := make(chan error, 1)
+	RegisterEndpointMethod(s, "wait", func(ctx context.Context, data string) (string, *Error) {
+		select {
+		case <-ctx.Done():
+			cancelled <- ctx.Err()
+		case <-time.After(5 * time.Second):
+			cancelled <- nil
+		}
+		return "", nil
+	})
+
+	call := Go[[]string, string](context.Background(), c, "wait", []string{"data"})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := call.Wait(ctx)
+	assert.ErrorIs(err, context.DeadlineExceeded)
+
+	call.Cancel()
+	_, err = call.Wait(context.Background())
+	assert.ErrorIs(err, context.Canceled)
+	assert.ErrorIs(<-cancelled, context.Canceled)
+}
@@ -0,0 +1,143 @@
+package jsonrpc2
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+)
+
+var (
+	ErrBatchNotSent     = errors.New("batch not sent")
+	ErrBatchAlreadySent = errors.New("batch already sent")
+)
+
+// BatchBuilder collects requests and notifications with different param and result types
+// and sends them as a single batch. Calls are added with AddBatchRequest and AddBatchNotification.
+type BatchBuilder struct {
+	c       EndpointClient
+	mutex   sync.Mutex
+	sent    bool
+	calls   []*ClientCall
+	handles []batchHandle
+}
+
+// batchHandle is resolved with response of the request or error of the whole batch
+type batchHandle interface {
+	resolve(response *Response[json.RawMessage], err error)
+}
+
+// BatchCall is a handle of request added to the batch, it is resolved when the batch is sent.
+type BatchCall[TResult Result] struct {
+	response *Response[TResult]
+	err      error
+}
+
+// NewBatch creates batch builder sending calls through the endpoint.
+func NewBatch(c EndpointClient) *BatchBuilder {
+	return &BatchBuilder{c: c}
+}
+
+// AddBatchRequest adds request to the batch and returns handle resolved by Send.
+func AddBatchRequest[TParams Params, TResult Result](b *BatchBuilder, method string, params TParams) *BatchCall[TResult] {
+	call := &BatchCall[TResult]{err: ErrBatchNotSent}
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.sent {
+		call.err = ErrBatchAlreadySent
+		return call
+	}
+	id, err := newRequestId(b.c)
+	if err != nil {
+		call.err = err
+		return call
+	}
+	b.calls = append(b.calls, &ClientCall{Id: id, Method: method, Params: params})
+	b.handles = append(b.handles, call)
+	return call
+}
+
+// AddBatchNotification adds notification to the batch.
+func AddBatchNotification[TParams Params](b *BatchBuilder, method string, params TParams) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.sent {
+		return
+	}
+	b.calls = append(b.calls, &ClientCall{Method: method, Params: params, IsNotification: true})
+}
+
+// Len returns number of calls in the batch including notifications
+func (b *BatchBuilder) Len() int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return len(b.calls)
+}
+
+// Send writes all calls as one batch and resolves handles of the requests. Error responses
+// and results which can not be decoded are reported by the handles. Returned error means
+// the batch as a whole failed and all handles are resolved with it.
+func (b *BatchBuilder) Send(ctx context.Context) error {
+	b.mutex.Lock()
+	if b.sent {
+		b.mutex.Unlock()
+		return ErrBatchAlreadySent
+	}
+	b.sent = true
+	calls, handles := b.calls, b.handles
+	b.mutex.Unlock()
+
+	if len(calls) == 0 {
+		return nil
+	}
+	err := b.send(ctx, calls, handles)
+	if err != nil {
+		for _, handle := range handles {
+			handle.resolve(nil, err)
+		}
+	}
+	return err
+}
+
+func (b *BatchBuilder) send(ctx context.Context, calls []*ClientCall, handles []batchHandle) error {
+	if b.c == nil {
+		return ErrInvalidEndpoint
+	}
+	if b.c.IsClosed() {
+		return ErrStreamClosed
+	}
+	responses, err := invokeClientCalls(ctx, b.c, true, calls)
+	if err != nil {
+		return err
+	}
+	if len(responses) != len(handles) {
+		return ErrInternalInvalidMessageStructure
+	}
+	for i, handle := range handles {
+		handle.resolve(responses[i], nil)
+	}
+	return nil
+}
+
+func (call *BatchCall[TResult]) resolve(response *Response[json.RawMessage], err error) {
+	if err != nil {
+		call.response, call.err = nil, err
+		return
+	}
+	call.response, call.err = decodeResponse[TResult](response)
+}
+
+// Response returns response of the request. Error is returned if the batch was not sent,
+// failed or the result could not be decoded, error responses are returned as is.
+func (call *BatchCall[TResult]) Response() (*Response[TResult], error) {
+	return call.response, call.err
+}
+
+// Result returns result of the request or error including error response of the request.
+func (call *BatchCall[TResult]) Result() (TResult, error) {
+	var zero TResult
+	if call.err != nil {
+		return zero, call.err
+	}
+	return call.response.Unwrap()
+}
@@ -0,0 +1,82 @@
+package jsonrpc2
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testBalanceParams struct {
+	Address string `json:"address"`
+}
+
+func TestStreamBatchBuilder(t *testing.T) {
+	assert := assert.New(t)
+	connA, connB := net.Pipe()
+	s := NewStreamEndpoint(context.Background(), NewPlainObjectStream(connA))
+	c := NewStreamEndpoint(context.Background(), NewPlainObjectStream(connB))
+	notified := make(chan string, 1)
+	RegisterEndpointMethod(s, "getBlock", func(ctx context.Context, height int64) (map[string]int64, *Error) {
+		return map[string]int64{"height": height}, nil
+	})
+	RegisterEndpointMethod(s, "getBalance", func(ctx context.Context, p testBalanceParams) (string, *Error) {
+		if p.Address == "" {
+			return "", NewInvalidParamsWithData("address is required")
+		}
+		return "100", nil
+	})
+	RegisterEndpointMethod(s, "log", func(ctx context.Context, Message string) (interface{}, *Error) {
+		notified <- Message
+		return nil, nil
+	})
+
+	batch := NewBatch(c)
+	block := AddBatchRequest[[]int64, map[string]int64](batch, "getBlock", []int64{10})
+	balance := AddBatchRequest[testBalanceParams, string](batch, "getBalance", testBalanceParams{Address: "tz1"})
+	invalid := AddBatchRequest[testBalanceParams, string](batch, "getBalance", testBalanceParams{})
+	mismatched := AddBatchRequest[[]int64, int64](batch, "getBlock", []int64{1})
+	AddBatchNotification(batch, "log", []string{"sent"})
+	assert.Equal(5, batch.Len())
+
+	_, err := block.Result()
+	assert.ErrorIs(err, ErrBatchNotSent)
+
+	assert.Nil(batch.Send(context.Background()))
+	blockResult, err := block.Result()
+	assert.Nil(err)
+	assert.Equal(map[string]int64{"height": 10}, blockResult)
+	balanceResult, err := balance.Result()
+	assert.Nil(err)
+	assert.Equal("100", balanceResult)
+
+	response, err := invalid.Response()
+	assert.Nil(err)
+	if assert.NotNil(response.Error) {
+		assert.Equal(-32602, response.Error.Code)
+	}
+	_, err = invalid.Result()
+	assert.NotNil(err)
+
+	_, err = mismatched.Response()
+	assert.NotNil(err)
+	assert.Equal("sent", <-notified)
+
+	assert.ErrorIs(batch.Send(context.Background()), ErrBatchAlreadySent)
+	_, err = AddBatchRequest[[]int64, int64](batch, "getBlock", []int64{1}).Result()
+	assert.ErrorIs(err, ErrBatchAlreadySent)
+}
+
+func TestStreamBatchBuilderClosed(t *testing.T) {
+	assert := assert.New(t)
+	connA, _ := net.Pipe()
+	c := NewStreamEndpoint(context.Background(), NewPlainObjectStream(connA))
+	c.Close()
+
+	batch := NewBatch(c)
+	call := AddBatchRequest[[]int64, int64](batch, "getBlock", []int64{1})
+	assert.ErrorIs(batch.Send(context.Background()), ErrStreamClosed)
+	_, err := call.Result()
+	assert.ErrorIs(err, ErrStreamClosed)
+}
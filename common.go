@@ -2,16 +2,15 @@ package jsonrpc2
 
 import (
 	"context"
-
-	"github.com/google/uuid"
+	"encoding/json"
 )
 
 // register method to server endpoint
-func RegisterEndpointMethod[TParam Params, TResult Result](c EndpointServer, method string, handler RpcMethod[TParam, TResult]) {
+func RegisterEndpointMethod[TParam Params, TResult Result](c EndpointServer, method string, handler RpcMethod[TParam, TResult], opts ...MethodOption) {
 	if c == nil {
 		return
 	}
-	RegisterMethod(c.GetMethods(), method, handler)
+	RegisterMethod(c.GetMethods(), method, handler, opts...)
 }
 
 // request
@@ -19,33 +18,32 @@ func Request[TParams Params, TResult Result](ctx context.Context, c EndpointClie
 	if c == nil {
 		return nil, ErrInvalidEndpoint
 	}
-	uuid, err := uuid.NewRandom()
+	requestId, err := newRequestId(c)
 	if err != nil {
 		return nil, err
 	}
-	requestId := uuid.String()
+	return sendRequest[TParams, TResult](ctx, c, requestId, method, params)
+}
+
+// RequestWithId sends request with the id instead of one generated by the endpoint.
+// The id must not be used by another pending request of the endpoint.
+func RequestWithId[TId Id, TParams Params, TResult Result](ctx context.Context, c EndpointClient, id TId, method string, params TParams) (*Response[TResult], error) {
+	if c == nil {
+		return nil, ErrInvalidEndpoint
+	}
+	return sendRequest[TParams, TResult](ctx, c, id, method, params)
+}
+
+func sendRequest[TParams Params, TResult Result](ctx context.Context, c EndpointClient, requestId interface{}, method string, params TParams) (*Response[TResult], error) {
 	if c.IsClosed() {
 		return nil, ErrStreamClosed
 	}
 
-	ch := c.RegisterPendingRequest(requestId)
-	defer c.UnregisterPendingRequest(requestId)
-
-	request := NewRequest(requestId, method, params)
-	if err := c.WriteObject(request); err != nil {
+	responses, err := invokeClientCalls(ctx, c, false, []*ClientCall{{Id: requestId, Method: method, Params: params}})
+	if err != nil {
 		return nil, err
 	}
-	select {
-	case <-ctx.Done():
-		return nil, ctx.Err()
-	case responseMsg, ok := <-ch:
-		if !ok {
-			return nil, ErrStreamClosed
-		}
-
-		response, err := MessageToResponse[TResult](&responseMsg)
-		return response, err
-	}
+	return decodeResponse[TResult](responses[0])
 }
 
 func RequestTo[TParams Params, TResult Result](ctx context.Context, c EndpointClient, method string, params TParams, result *Response[TResult]) error {
@@ -65,14 +63,8 @@ func Notify[TParams Params](ctx context.Context, c EndpointClient, method string
 	if c.IsClosed() {
 		return ErrStreamClosed
 	}
-	notification := NewNotification(method, params)
-	if err := c.WriteObject(notification); err != nil {
-		if err == ErrEmptyResponse { // we do not expect response here
-			return nil
-		}
-		return err
-	}
-	return nil
+	_, err := invokeClientCalls(ctx, c, false, []*ClientCall{{Method: method, Params: params, IsNotification: true}})
+	return err
 }
 
 type RequestInfo[TParams Params] struct {
@@ -86,46 +78,33 @@ func Batch[TParams Params, TResult Result](ctx context.Context, c EndpointClient
 	if c == nil {
 		return nil, ErrInvalidEndpoint
 	}
-	rpcRequests := make([]*request[TParams], 0, len(requests))
-	resultChannels := make([]<-chan message, 0, len(requests))
+	calls := make([]*ClientCall, 0, len(requests))
 	for _, request := range requests {
-		uuid, err := uuid.NewRandom()
-		if err != nil {
-			return nil, err
-		}
-		requestId := uuid.String()
 		if c.IsClosed() {
 			return nil, ErrStreamClosed
 		}
 		if request.IsNotification {
-			rpcRequests = append(rpcRequests, NewNotification(request.Method, request.Params))
+			calls = append(calls, &ClientCall{Method: request.Method, Params: request.Params, IsNotification: true})
 			continue
 		}
-		resultChannels = append(resultChannels, c.RegisterPendingRequest(requestId))
-		defer c.UnregisterPendingRequest(requestId)
-
-		rpcRequests = append(rpcRequests, NewRequest(requestId, request.Method, request.Params))
+		requestId, err := newRequestId(c)
+		if err != nil {
+			return nil, err
+		}
+		calls = append(calls, &ClientCall{Id: requestId, Method: request.Method, Params: request.Params})
 	}
 
-	if err := c.WriteObject(rpcRequests); err != nil {
+	responses, err := invokeClientCalls(ctx, c, true, calls)
+	if err != nil {
 		return nil, err
 	}
-	results := make([]*Response[TResult], 0, len(requests))
-	for _, ch := range resultChannels {
-		select {
-		case <-ctx.Done():
-			return nil, ctx.Err()
-		case responseMsg, ok := <-ch:
-			if !ok {
-				return nil, ErrStreamClosed
-			}
-
-			response, err := MessageToResponse[TResult](&responseMsg)
-			if err != nil {
-				return nil, err
-			}
-			results = append(results, response)
+	results := make([]*Response[TResult], 0, len(responses))
+	for _, rawResponse := range responses {
+		response, err := decodeResponse[TResult](rawResponse)
+		if err != nil {
+			return nil, err
 		}
+		results = append(results, response)
 	}
 	return results, nil
 }
@@ -138,3 +117,126 @@ func BatchTo[TParams Params, TResult Result](ctx context.Context, c EndpointClie
 	copy(results, r)
 	return nil
 }
+
+// invokeClientCalls passes calls through client interceptors of the endpoint and sends them
+func invokeClientCalls(ctx context.Context, c EndpointClient, isBatch bool, calls []*ClientCall) ([]*Response[json.RawMessage], error) {
+	invoker := ChainClientInterceptors(newClientInvoker(c, isBatch), getClientConfig(c).interceptors...)
+	return invoker(ctx, calls)
+}
+
+// newClientInvoker creates invoker writing calls to the endpoint and waiting for their responses
+func newClientInvoker(c EndpointClient, isBatch bool) ClientInvoker {
+	strict := isStrictEndpoint(c)
+	return func(ctx context.Context, calls []*ClientCall) ([]*Response[json.RawMessage], error) {
+		if c.IsClosed() {
+			return nil, ErrStreamClosed
+		}
+		rpcRequests := make([]*request[interface{}], 0, len(calls))
+		resultChannels := make([]<-chan Message, 0, len(calls))
+		resultIds := make([]interface{}, 0, len(calls))
+		for _, call := range calls {
+			rpcRequest := &request[interface{}]{
+				messageBase: messageBase{Version: jsonRpcVersion},
+				Method:      call.Method,
+				Params:      call.Params,
+				Meta:        call.Meta,
+			}
+			if strict {
+				params, err := structuredParams(call.Params)
+				if err != nil {
+					return nil, err
+				}
+				rpcRequest.Params = params
+			}
+			if !call.IsNotification {
+				rpcRequest.Id = call.Id
+				resultChannels = append(resultChannels, c.RegisterPendingRequest(call.Id))
+				resultIds = append(resultIds, call.Id)
+				defer c.UnregisterPendingRequest(call.Id)
+			}
+			rpcRequests = append(rpcRequests, rpcRequest)
+		}
+
+		var obj interface{} = rpcRequests
+		if !isBatch && len(rpcRequests) == 1 {
+			obj = rpcRequests[0]
+		}
+		if err := c.WriteObject(obj); err != nil {
+			if err == ErrEmptyResponse && len(resultChannels) == 0 { // we do not expect response here
+				return nil, nil
+			}
+			return nil, err
+		}
+		if written, ok := ctx.Value(writtenNotifyContextKey).(func()); ok {
+			written()
+		}
+
+		responses := make([]*Response[json.RawMessage], 0, len(resultChannels))
+		for i, ch := range resultChannels {
+			select {
+			case <-ctx.Done():
+				cancelPendingRequests(c, calls, len(resultChannels)-i)
+				return nil, ctx.Err()
+			case responseMsg, ok := <-ch:
+				if !ok {
+					return nil, pendingError(c, resultIds[i])
+				}
+
+				response, err := MessageToResponse[json.RawMessage](&responseMsg)
+				if err != nil {
+					return nil, err
+				}
+				responses = append(responses, response)
+			}
+		}
+		return responses, nil
+	}
+}
+
+type pendingErrorClient interface {
+	pendingError(requestId interface{}) error
+}
+
+// pendingError returns error of pending request whose response channel was closed
+func pendingError(c EndpointClient, requestId interface{}) error {
+	if client, ok := c.(pendingErrorClient); ok {
+		return client.pendingError(requestId)
+	}
+	return ErrStreamClosed
+}
+
+type cancelParams struct {
+	Id interface{} `json:"id"`
+}
+
+// cancelPendingRequests notifies the remote endpoint about last n requests
+// which are not going to be awaited anymore
+func cancelPendingRequests(c EndpointClient, calls []*ClientCall, n int) {
+	cancelMethod := getClientConfig(c).cancelMethod
+	if cancelMethod == "" {
+		return
+	}
+	for i := len(calls) - 1; i >= 0 && n > 0; i-- {
+		if calls[i].IsNotification {
+			continue
+		}
+		n--
+		if err := c.WriteObject(NewNotification(cancelMethod, cancelParams{Id: calls[i].Id})); err != nil {
+			return
+		}
+	}
+}
+
+func decodeResponse[TResult Result](rawResponse *Response[json.RawMessage]) (*Response[TResult], error) {
+	response := &Response[TResult]{
+		messageBase: rawResponse.messageBase,
+		Id:          rawResponse.Id,
+		Error:       rawResponse.Error,
+	}
+	if rawResponse.IsSuccess() && rawResponse.Result != nil {
+		if err := json.Unmarshal(rawResponse.Result, &response.Result); err != nil {
+			return nil, err
+		}
+	}
+	return response, nil
+}
@@ -0,0 +1,226 @@
+package jsonrpc2
+
+import (
+	"sync"
+)
+
+// DefaultOverloadedErrorCode is code of server error returned to requests rejected by OverflowReject policy
+const DefaultOverloadedErrorCode = -32005
+
+// OverflowPolicy decides what happens with incoming messages when all handlers are busy
+// and the queue is full
+type OverflowPolicy int
+
+const (
+	// OverflowBlock stops reading from the stream until a handler is free. While blocked
+	// responses to requests sent through the endpoint are not read either.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowReject responds to requests with server error and drops notifications
+	OverflowReject
+	// OverflowDropNotifications drops notifications and blocks on requests
+	OverflowDropNotifications
+)
+
+// ConcurrencyLimits limits number of messages processed by StreamEndpoint at once.
+// Single message or whole batch is processed by one handler.
+type ConcurrencyLimits struct {
+	// MaxHandlers is maximum number of concurrently running handlers, 0 means unlimited
+	MaxHandlers int
+	// MaxQueued is maximum number of messages waiting for a free handler or for preceding
+	// messages with the same ordering key. It is enforced on its own when MaxHandlers is 0,
+	// 0 means no queue if MaxHandlers is set and unlimited queue otherwise.
+	MaxQueued int
+	Policy    OverflowPolicy
+	// RejectCode is code of server error returned by OverflowReject policy, DefaultOverloadedErrorCode if 0
+	RejectCode int
+}
+
+// ConcurrencyStats is snapshot of message processing state of the endpoint
+type ConcurrencyStats struct {
+	ActiveHandlers int
+	QueuedMessages int
+	// Rejected is total number of requests rejected because of overflow
+	Rejected uint64
+	// Dropped is total number of notifications dropped because of overflow
+	Dropped uint64
+}
+
+// scheduledHandler processes messages received in one object
+type scheduledHandler struct {
+	handler func()
+	key     string
+	ordered bool
+}
+
+// messageScheduler runs handlers of received messages respecting concurrency limits and ordering
+type messageScheduler struct {
+	mutex    sync.Mutex
+	free     *sync.Cond
+	limits   ConcurrencyLimits
+	ordering OrderingKey
+
+	active int
+	// handlers ready to run waiting for a free handler slot
+	ready []*scheduledHandler
+	// handlers waiting for a running handler with the same key, key is present while it is running
+	waiting  map[string][]*scheduledHandler
+	queued   int
+	rejected uint64
+	dropped  uint64
+}
+
+func newMessageScheduler() *messageScheduler {
+	s := &messageScheduler{waiting: make(map[string][]*scheduledHandler)}
+	s.free = sync.NewCond(&s.mutex)
+	return s
+}
+
+// UseConcurrencyLimits limits number of concurrently processed messages.
+// Should be set before the first message is received, in ConnOpt passed to NewStreamEndpoint.
+func (c *StreamEndpoint) UseConcurrencyLimits(limits ConcurrencyLimits) {
+	if limits.RejectCode == 0 {
+		limits.RejectCode = DefaultOverloadedErrorCode
+	}
+	c.scheduler.mutex.Lock()
+	defer c.scheduler.mutex.Unlock()
+	c.scheduler.limits = limits
+}
+
+// ConcurrencyStats returns number of running handlers, queue depth and overflow counters.
+func (c *StreamEndpoint) ConcurrencyStats() ConcurrencyStats {
+	s := c.scheduler
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return ConcurrencyStats{
+		ActiveHandlers: s.active,
+		QueuedMessages: s.queued,
+		Rejected:       s.rejected,
+		Dropped:        s.dropped,
+	}
+}
+
+// isFull reports whether the handler would have to be queued and the queue is full
+func (s *messageScheduler) isFull(scheduled *scheduledHandler) bool {
+	if s.limits.MaxHandlers <= 0 && s.limits.MaxQueued <= 0 {
+		return false
+	}
+	return s.mustQueue(scheduled) && s.queued >= s.limits.MaxQueued
+}
+
+// mustQueue reports whether the handler can not run right away
+func (s *messageScheduler) mustQueue(scheduled *scheduledHandler) bool {
+	if scheduled.ordered {
+		if _, ok := s.waiting[scheduled.key]; ok {
+			return true
+		}
+	}
+	return !s.hasFreeHandler()
+}
+
+func (s *messageScheduler) hasFreeHandler() bool {
+	return s.limits.MaxHandlers <= 0 || s.active < s.limits.MaxHandlers
+}
+
+// dispatch runs handler of received messages respecting concurrency limits and ordering.
+// Returns false if messages were not accepted because of overflow.
+func (c *StreamEndpoint) dispatch(messages []Message, handler func()) bool {
+	s := c.scheduler
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	scheduled := &scheduledHandler{handler: handler}
+	scheduled.key, scheduled.ordered = s.orderingKey(messages)
+	for s.isFull(scheduled) {
+		switch {
+		case s.limits.Policy == OverflowReject:
+			s.rejected += uint64(countRequests(messages, c.isStrict()))
+			s.dropped += uint64(len(messages) - countRequests(messages, c.isStrict()))
+			return false
+		case s.limits.Policy == OverflowDropNotifications && countRequests(messages, c.isStrict()) == 0:
+			s.dropped += uint64(len(messages))
+			return false
+		}
+		s.free.Wait()
+	}
+
+	if scheduled.ordered {
+		if waiting, ok := s.waiting[scheduled.key]; ok {
+			s.waiting[scheduled.key] = append(waiting, scheduled)
+			s.queued++
+			return true
+		}
+		s.waiting[scheduled.key] = nil
+	}
+	if !s.hasFreeHandler() {
+		s.ready = append(s.ready, scheduled)
+		s.queued++
+		return true
+	}
+	s.active++
+	go c.runHandlers(scheduled)
+	return true
+}
+
+// runHandlers runs handler and then handlers which became ready until there are none
+func (c *StreamEndpoint) runHandlers(scheduled *scheduledHandler) {
+	s := c.scheduler
+	for scheduled != nil {
+		scheduled.handler()
+		c.endHandler()
+
+		s.mutex.Lock()
+		if scheduled.ordered {
+			if waiting := s.waiting[scheduled.key]; len(waiting) > 0 {
+				s.waiting[scheduled.key] = waiting[1:]
+				s.ready = append(s.ready, waiting[0])
+			} else {
+				delete(s.waiting, scheduled.key)
+			}
+		}
+		scheduled = nil
+		if len(s.ready) > 0 {
+			scheduled = s.ready[0]
+			s.ready = s.ready[1:]
+			s.queued--
+		} else {
+			s.active--
+		}
+		s.free.Signal()
+		s.mutex.Unlock()
+	}
+}
+
+func (c *StreamEndpoint) overloadedError() *Error {
+	c.scheduler.mutex.Lock()
+	defer c.scheduler.mutex.Unlock()
+	return NewServerErrorWithData(c.scheduler.limits.RejectCode, "too many requests in flight")
+}
+
+// rejectMessages responds to requests which are not going to be processed with the error
+func (c *StreamEndpoint) rejectMessages(messages []Message, isBatch bool, err *Error) {
+	results := make([]interface{}, 0, len(messages))
+	for _, rpcMsg := range messages {
+		if !isRequest(&rpcMsg, c.isStrict()) {
+			continue
+		}
+		c.logger.Debug("jsonrpc2: rejecting request", "request_id", rpcMsg.Id, "reason", err.Data)
+		results = append(results, err.ToResponse(rpcMsg.Id))
+	}
+	c.writeResults(results, isBatch)
+}
+
+// isRequest reports whether the message is a request expecting response, classified the same way
+// as messages are handled
+func isRequest(rpcMsg *Message, strict bool) bool {
+	kind, _ := rpcMsg.getKind(strict)
+	return kind != NOTIFICATION_KIND && rpcMsg.Id != nil
+}
+
+func countRequests(messages []Message, strict bool) int {
+	count := 0
+	for i := range messages {
+		if isRequest(&messages[i], strict) {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,139 @@
+package jsonrpc2
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newLimitedStreamEndpoints(limits ConcurrencyLimits, release <-chan struct{}, opts ...ConnOpt) (*StreamEndpoint, *StreamEndpoint, *atomic.Int32) {
+	connA, connB := net.Pipe()
+	opts = append(opts, func(s *StreamEndpoint) {
+		s.UseConcurrencyLimits(limits)
+	})
+	s := NewStreamEndpoint(context.Background(), NewPlainObjectStream(connA), opts...)
+	c := NewStreamEndpoint(context.Background(), NewPlainObjectStream(connB))
+
+	var maxActive atomic.Int32
+	var active atomic.Int32
+	RegisterEndpointMethod(s, "wait", func(ctx context.Context, n int) (int, *Error) {
+		current := active.Add(1)
+		defer active.Add(-1)
+		for {
+			seen := maxActive.Load()
+			if current <= seen || maxActive.CompareAndSwap(seen, current) {
+				break
+			}
+		}
+		<-release
+		return n, nil
+	})
+	return s, c, &maxActive
+}
+
+func TestStreamConcurrencyLimitsReject(t *testing.T) {
+	assert := assert.New(t)
+	release := make(chan struct{})
+	s, c, maxActive := newLimitedStreamEndpoints(ConcurrencyLimits{MaxHandlers: 2, MaxQueued: 1, Policy: OverflowReject}, release)
+
+	calls := make([]*Call[int], 0, 3)
+	for i := 1; i <= 3; i++ {
+		calls = append(calls, Go[[]int, int](context.Background(), c, "wait", []int{i}))
+	}
+	assert.Eventually(func() bool {
+		return s.ConcurrencyStats() == ConcurrencyStats{ActiveHandlers: 2, QueuedMessages: 1}
+	}, time.Second, 10*time.Millisecond)
+
+	response, err := Request[[]int, int](context.Background(), c, "wait", []int{4})
+	assert.Nil(err)
+	if assert.NotNil(response.Error) {
+		assert.Equal(DefaultOverloadedErrorCode, response.Error.Code)
+	}
+	assert.Equal(uint64(1), s.ConcurrencyStats().Rejected)
+
+	close(release)
+	for i, call := range calls {
+		response, err := call.Wait(context.Background())
+		assert.Nil(err)
+		assert.Equal(i+1, response.Result)
+	}
+	assert.Equal(int32(2), maxActive.Load())
+	assert.Eventually(func() bool {
+		return s.ConcurrencyStats() == ConcurrencyStats{Rejected: 1}
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestStreamConcurrencyLimitsBlock(t *testing.T) {
+	assert := assert.New(t)
+	release := make(chan struct{})
+	s, c, maxActive := newLimitedStreamEndpoints(ConcurrencyLimits{MaxHandlers: 1}, release)
+
+	var wg sync.WaitGroup
+	for i := 1; i <= 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			response, err := Request[[]int, int](context.Background(), c, "wait", []int{i})
+			assert.Nil(err)
+			assert.Equal(i, response.Result)
+		}()
+	}
+	assert.Eventually(func() bool {
+		return s.ConcurrencyStats().ActiveHandlers == 1
+	}, time.Second, 10*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(ConcurrencyStats{ActiveHandlers: 1}, s.ConcurrencyStats())
+
+	close(release)
+	wg.Wait()
+	assert.Equal(int32(1), maxActive.Load())
+}
+
+func TestStreamConcurrencyLimitsDropNotifications(t *testing.T) {
+	assert := assert.New(t)
+	release := make(chan struct{})
+	s, c, _ := newLimitedStreamEndpoints(ConcurrencyLimits{MaxHandlers: 1, Policy: OverflowDropNotifications}, release)
+
+	call := Go[[]int, int](context.Background(), c, "wait", []int{1})
+	assert.Eventually(func() bool {
+		return s.ConcurrencyStats().ActiveHandlers == 1
+	}, time.Second, 10*time.Millisecond)
+	assert.Nil(Notify(context.Background(), c, "wait", []int{2}))
+	assert.Eventually(func() bool {
+		return s.ConcurrencyStats().Dropped == 1
+	}, time.Second, 10*time.Millisecond)
+
+	close(release)
+	response, err := call.Wait(context.Background())
+	assert.Nil(err)
+	assert.Equal(1, response.Result)
+}
+
+func TestStreamConcurrencyLimitsLenientNotifications(t *testing.T) {
+	assert := assert.New(t)
+	release := make(chan struct{})
+	s, c, _ := newLimitedStreamEndpoints(ConcurrencyLimits{MaxHandlers: 1, Policy: OverflowDropNotifications}, release, func(s *StreamEndpoint) {
+		s.UseValidation(LenientValidation)
+	})
+
+	call := Go[[]int, int](context.Background(), c, "wait", []int{1})
+	assert.Eventually(func() bool {
+		return s.ConcurrencyStats().ActiveHandlers == 1
+	}, time.Second, 10*time.Millisecond)
+	// lenient endpoint handles request with null id as notification, so it is dropped too
+	assert.Nil(c.writeObject(json.RawMessage(`{"jsonrpc": "2.0", "method": "wait", "params": [2], "id": null}`)))
+	assert.Eventually(func() bool {
+		return s.ConcurrencyStats().Dropped == 1
+	}, time.Second, 10*time.Millisecond)
+
+	close(release)
+	response, err := call.Wait(context.Background())
+	assert.Nil(err)
+	assert.Equal(1, response.Result)
+}
@@ -0,0 +1,249 @@
+package jsonrpc2
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testSubtractParams struct {
+	Minuend    int `json:"minuend" jsonrpc:"0"`
+	Subtrahend int `json:"subtrahend" jsonrpc:"1"`
+}
+
+// registerSpecMethods registers methods used in examples of JSON-RPC 2.0 specification
+func registerSpecMethods(reg RpcMethodRegistry) {
+	RegisterMethod(reg, "subtract", func(ctx context.Context, p testSubtractParams) (int, *Error) {
+		return p.Minuend - p.Subtrahend, nil
+	})
+	RegisterMethod(reg, "sum", func(ctx context.Context, p []int) (int, *Error) {
+		sum := 0
+		for _, n := range p {
+			sum += n
+		}
+		return sum, nil
+	})
+	RegisterMethod(reg, "update", func(ctx context.Context, p []int) (interface{}, *Error) {
+		return nil, nil
+	})
+	RegisterMethod(reg, "foobar", func(ctx context.Context, p interface{}) (interface{}, *Error) {
+		return nil, nil
+	})
+	RegisterMethod(reg, "notify_hello", func(ctx context.Context, p []int) (interface{}, *Error) {
+		return nil, nil
+	})
+	RegisterMethod(reg, "get_data", func(ctx context.Context, p interface{}) ([]interface{}, *Error) {
+		return []interface{}{"hello", 5}, nil
+	})
+}
+
+// specExamples are examples from https://www.jsonrpc.org/specification#examples
+// extended with other rules of the specification, empty response means no response
+var specExamples = []struct {
+	name     string
+	request  string
+	response string
+	// stream is closed after parse error, so the following messages are not processed
+	parseError bool
+}{
+	{"positional parameters", `{"jsonrpc": "2.0", "method": "subtract", "params": [42, 23], "id": 1}`, `{"jsonrpc": "2.0", "result": 19, "id": 1}`, false},
+	{"positional parameters reversed", `{"jsonrpc": "2.0", "method": "subtract", "params": [23, 42], "id": 2}`, `{"jsonrpc": "2.0", "result": -19, "id": 2}`, false},
+	{"named parameters", `{"jsonrpc": "2.0", "method": "subtract", "params": {"subtrahend": 23, "minuend": 42}, "id": 3}`, `{"jsonrpc": "2.0", "result": 19, "id": 3}`, false},
+	{"named parameters reordered", `{"jsonrpc": "2.0", "method": "subtract", "params": {"minuend": 42, "subtrahend": 23}, "id": 4}`, `{"jsonrpc": "2.0", "result": 19, "id": 4}`, false},
+	{"string id", `{"jsonrpc": "2.0", "method": "subtract", "params": [42, 23], "id": "abc"}`, `{"jsonrpc": "2.0", "result": 19, "id": "abc"}`, false},
+	{"zero result", `{"jsonrpc": "2.0", "method": "subtract", "params": [42, 42], "id": 5}`, `{"jsonrpc": "2.0", "result": 0, "id": 5}`, false},
+	{"null result", `{"jsonrpc": "2.0", "method": "update", "params": [1], "id": 6}`, `{"jsonrpc": "2.0", "result": null, "id": 6}`, false},
+	{"null id", `{"jsonrpc": "2.0", "method": "subtract", "params": [42, 23], "id": null}`, `{"jsonrpc": "2.0", "result": 19, "id": null}`, false},
+	{"notification", `{"jsonrpc": "2.0", "method": "update", "params": [1,2,3,4,5]}`, ``, false},
+	{"notification without params", `{"jsonrpc": "2.0", "method": "foobar"}`, ``, false},
+	{"non-existent method", `{"jsonrpc": "2.0", "method": "foobar2", "id": "1"}`, `{"jsonrpc": "2.0", "error": {"code": -32601, "message": "Method not found"}, "id": "1"}`, false},
+	{"invalid JSON", `{"jsonrpc": "2.0", "method": "foobar, "params": "bar", "baz]`, `{"jsonrpc": "2.0", "error": {"code": -32700, "message": "Parse error"}, "id": null}`, true},
+	{"invalid request object", `{"jsonrpc": "2.0", "method": 1, "params": "bar"}`, `{"jsonrpc": "2.0", "error": {"code": -32600, "message": "Invalid Request"}, "id": null}`, false},
+	{"scalar params", `{"jsonrpc": "2.0", "method": "foobar", "params": "bar", "id": 5}`, `{"jsonrpc": "2.0", "error": {"code": -32600, "message": "Invalid Request"}, "id": 5}`, false},
+	{"missing version", `{"method": "foobar", "id": 6}`, `{"jsonrpc": "2.0", "error": {"code": -32600, "message": "Invalid Request"}, "id": 6}`, false},
+	{"batch invalid JSON", `[
+		{"jsonrpc": "2.0", "method": "sum", "params": [1,2,4], "id": "1"},
+		{"jsonrpc": "2.0", "method"
+	]`, `{"jsonrpc": "2.0", "error": {"code": -32700, "message": "Parse error"}, "id": null}`, true},
+	{"empty batch", `[]`, `{"jsonrpc": "2.0", "error": {"code": -32600, "message": "Invalid Request"}, "id": null}`, false},
+	{"invalid batch", `[1]`, `[{"jsonrpc": "2.0", "error": {"code": -32600, "message": "Invalid Request"}, "id": null}]`, false},
+	{"invalid batch of three", `[1,2,3]`, `[
+		{"jsonrpc": "2.0", "error": {"code": -32600, "message": "Invalid Request"}, "id": null},
+		{"jsonrpc": "2.0", "error": {"code": -32600, "message": "Invalid Request"}, "id": null},
+		{"jsonrpc": "2.0", "error": {"code": -32600, "message": "Invalid Request"}, "id": null}
+	]`, false},
+	{"batch", `[
+		{"jsonrpc": "2.0", "method": "sum", "params": [1,2,4], "id": "1"},
+		{"jsonrpc": "2.0", "method": "notify_hello", "params": [7]},
+		{"jsonrpc": "2.0", "method": "subtract", "params": [42,23], "id": "2"},
+		{"foo": "boo"},
+		{"jsonrpc": "2.0", "method": "foo.get", "params": {"name": "myself"}, "id": "5"},
+		{"jsonrpc": "2.0", "method": "get_data", "id": "9"}
+	]`, `[
+		{"jsonrpc": "2.0", "result": 7, "id": "1"},
+		{"jsonrpc": "2.0", "result": 19, "id": "2"},
+		{"jsonrpc": "2.0", "error": {"code": -32600, "message": "Invalid Request"}, "id": null},
+		{"jsonrpc": "2.0", "error": {"code": -32601, "message": "Method not found"}, "id": "5"},
+		{"jsonrpc": "2.0", "result": ["hello", 5], "id": "9"}
+	]`, false},
+	{"batch of notifications", `[
+		{"jsonrpc": "2.0", "method": "notify_sum", "params": [1,2,4]},
+		{"jsonrpc": "2.0", "method": "notify_hello", "params": [7]}
+	]`, ``, false},
+}
+
+// normalizeResponse drops data of errors which are implementation specific
+func normalizeResponse(t *testing.T, response string) interface{} {
+	if response == "" {
+		return nil
+	}
+	var value interface{}
+	if err := json.Unmarshal([]byte(response), &value); err != nil {
+		t.Fatalf("invalid response %s: %v", response, err)
+	}
+	responses, isBatch := value.([]interface{})
+	if !isBatch {
+		responses = []interface{}{value}
+	}
+	for _, response := range responses {
+		if object, ok := response.(map[string]interface{}); ok {
+			if rpcErr, ok := object["error"].(map[string]interface{}); ok {
+				delete(rpcErr, "data")
+			}
+		}
+	}
+	return value
+}
+
+func TestHttpConformance(t *testing.T) {
+	mux := NewServerMux()
+	registerSpecMethods(mux.GetMethods())
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	for _, example := range specExamples {
+		t.Run(example.name, func(t *testing.T) {
+			response, err := http.Post(srv.URL, "application/json", bytes.NewReader([]byte(example.request)))
+			if !assert.Nil(t, err) {
+				return
+			}
+			defer response.Body.Close()
+			body, err := io.ReadAll(response.Body)
+			assert.Nil(t, err)
+			assert.Equal(t, normalizeResponse(t, example.response), normalizeResponse(t, string(body)))
+		})
+	}
+}
+
+func TestStreamConformance(t *testing.T) {
+	for _, example := range specExamples {
+		t.Run(example.name, func(t *testing.T) {
+			connA, connB := net.Pipe()
+			s := NewStreamEndpoint(context.Background(), NewPlainObjectStream(connA), func(s *StreamEndpoint) {
+				registerSpecMethods(s.GetMethods())
+				RegisterMethod(s.GetMethods(), "sentinel", func(ctx context.Context, p interface{}) (string, *Error) {
+					return "done", nil
+				})
+				s.UseOrdering(SequentialOrder())
+			})
+			defer s.Close()
+
+			// sentinel request marks the end of responses to the example
+			go func() {
+				connB.Write([]byte(example.request))
+				connB.Write([]byte(`{"jsonrpc": "2.0", "method": "sentinel", "id": "sentinel"}`))
+			}()
+			decoder := json.NewDecoder(connB)
+			responses := make([]string, 0, 1)
+			for {
+				var response json.RawMessage
+				if err := decoder.Decode(&response); err != nil {
+					assert.True(t, example.parseError, "stream closed: %v", err)
+					break
+				}
+				if bytes.Contains(response, []byte(`"sentinel"`)) {
+					break
+				}
+				responses = append(responses, string(response))
+			}
+			if example.response == "" {
+				assert.Empty(t, responses)
+				return
+			}
+			if assert.Len(t, responses, 1) {
+				assert.Equal(t, normalizeResponse(t, example.response), normalizeResponse(t, responses[0]))
+			}
+		})
+	}
+}
+
+func TestResponseConformance(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		response string
+		kind     MessageKind
+	}{
+		{`{"jsonrpc": "2.0", "result": 19, "id": 1}`, SUCCESS_RESPONSE_KIND},
+		{`{"jsonrpc": "2.0", "result": null, "id": 1}`, SUCCESS_RESPONSE_KIND},
+		{`{"jsonrpc": "2.0", "error": {"code": -32700, "message": "Parse error"}, "id": null}`, ERROR_RESPONSE_KIND},
+		{`{"jsonrpc": "2.0", "result": 19, "error": {"code": -32603, "message": "Internal error"}, "id": 1}`, INVALID_KIND},
+		{`{"jsonrpc": "2.0", "result": 19, "id": null}`, INVALID_KIND},
+		{`{"jsonrpc": "2.0", "result": 19}`, INVALID_KIND},
+		{`{"jsonrpc": "2.0", "result": 19, "id": {}}`, INVALID_KIND},
+		{`{"jsonrpc": "1.0", "result": 19, "id": 1}`, INVALID_KIND},
+	}
+	for _, test := range tests {
+		var rpcObj Object
+		assert.Nil(json.Unmarshal([]byte(test.response), &rpcObj))
+		kind, _ := rpcObj.GetSingleMessage().GetKind()
+		assert.Equal(test.kind, kind, test.response)
+	}
+}
+
+func TestLenientValidation(t *testing.T) {
+	assert := assert.New(t)
+	mux := NewServerMux()
+	mux.UseValidation(LenientValidation)
+	RegisterMethod(mux.GetMethods(), "hello", func(ctx context.Context, name string) (string, *Error) {
+		return "Hello " + name, nil
+	})
+	RegisterMethod(mux.GetMethods(), "echo", func(ctx context.Context, p interface{}) (interface{}, *Error) {
+		return p, nil
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	post := func(body string) string {
+		response, err := http.Post(srv.URL, "application/json", bytes.NewReader([]byte(body)))
+		assert.Nil(err)
+		defer response.Body.Close()
+		data, err := io.ReadAll(response.Body)
+		assert.Nil(err)
+		return string(data)
+	}
+	assert.JSONEq(`{"jsonrpc": "2.0", "result": "Hello World", "id": 1}`, post(`{"jsonrpc": "2.0", "method": "hello", "params": "World", "id": 1}`))
+	assert.Empty(post(`{"jsonrpc": "2.0", "method": "hello", "params": "World", "id": null}`))
+	assert.Empty(post(`[]`))
+
+	// lenient client sends scalar params as they are, strict one refuses to send them
+	c := NewHttpClientEndpoint(srv.URL, nil)
+	_, err := Request[string, string](context.Background(), c, "hello", "World")
+	assert.ErrorIs(err, ErrInternalInvalidParams)
+	response, err := Request[[]string, string](context.Background(), c, "hello", []string{"World"})
+	assert.Nil(err)
+	assert.Equal("Hello World", response.Result)
+	c.UseValidation(LenientValidation)
+	response, err = Request[string, string](context.Background(), c, "hello", "World")
+	assert.Nil(err)
+	assert.Equal("Hello World", response.Result)
+	echo, err := Request[string, interface{}](context.Background(), c, "echo", "World")
+	assert.Nil(err)
+	assert.Equal("World", echo.Result)
+}
@@ -0,0 +1,53 @@
+package jsonrpc2
+
+import (
+	"context"
+	"net/http"
+)
+
+type contextKey int
+
+const (
+	requestIdContextKey contextKey = iota
+	methodContextKey
+	httpRequestContextKey
+	endpointContextKey
+	writtenNotifyContextKey
+	panicRecoveryContextKey
+	outlivingHandlersContextKey
+)
+
+// RequestIdFromContext returns id of the request being handled, nil for notifications.
+// Numeric ids are returned as int64, or as json.Number if they are not representable as int64.
+func RequestIdFromContext(ctx context.Context) interface{} {
+	return ctx.Value(requestIdContextKey)
+}
+
+// MethodFromContext returns method of the request being handled.
+func MethodFromContext(ctx context.Context) string {
+	method, _ := ctx.Value(methodContextKey).(string)
+	return method
+}
+
+// HttpRequestFromContext returns http request the message was received in,
+// nil if the message was not received through ServerMux.
+func HttpRequestFromContext(ctx context.Context) *http.Request {
+	r, _ := ctx.Value(httpRequestContextKey).(*http.Request)
+	return r
+}
+
+// EndpointFromContext returns endpoint the message was received on, so handler can
+// send requests and notifications back to the peer. Returns nil if the message
+// was not received through StreamEndpoint.
+func EndpointFromContext(ctx context.Context) *StreamEndpoint {
+	endpoint, _ := ctx.Value(endpointContextKey).(*StreamEndpoint)
+	return endpoint
+}
+
+func withRequestContext(ctx context.Context, rpcMsg *Message) context.Context {
+	ctx = context.WithValue(ctx, methodContextKey, rpcMsg.Method)
+	if rpcMsg.Id != nil {
+		ctx = context.WithValue(ctx, requestIdContextKey, rpcMsg.Id.Value())
+	}
+	return ctx
+}
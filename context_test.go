@@ -0,0 +1,72 @@
+package jsonrpc2
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamRequestContext(t *testing.T) {
+	assert := assert.New(t)
+	connA, connB := net.Pipe()
+	s := NewStreamEndpoint(context.Background(), NewPlainObjectStream(connA))
+	c := NewStreamEndpoint(context.Background(), NewPlainObjectStream(connB))
+	RegisterEndpointMethod(c, "name", func(ctx context.Context, data string) (string, *Error) {
+		return "client", nil
+	})
+
+	var requestId interface{}
+	var method string
+	var httpRequest *http.Request
+	RegisterEndpointMethod(s, "hello", func(ctx context.Context, data string) (string, *Error) {
+		requestId = RequestIdFromContext(ctx)
+		method = MethodFromContext(ctx)
+		httpRequest = HttpRequestFromContext(ctx)
+		endpoint := EndpointFromContext(ctx)
+		if endpoint == nil {
+			return "", NewInternalError()
+		}
+		response, err := Request[[]string, string](ctx, endpoint, "name", []string{""})
+		if err != nil {
+			return "", NewInternalErrorWithData(err.Error())
+		}
+		name, _ := response.Unwrap()
+		return "Hello " + name, nil
+	})
+
+	response, err := Request[[]string, string](context.Background(), c, "hello", []string{""})
+	assert.Nil(err)
+	result, err := response.Unwrap()
+	assert.Nil(err)
+	assert.Equal("Hello client", result)
+	assert.Equal(response.Id, requestId)
+	assert.Equal("hello", method)
+	assert.Nil(httpRequest)
+}
+
+func TestHttpRequestContext(t *testing.T) {
+	assert := assert.New(t)
+	mux := NewServerMux()
+	var endpoint *StreamEndpoint
+	RegisterEndpointMethod(mux, "whoami", func(ctx context.Context, data string) (string, *Error) {
+		endpoint = EndpointFromContext(ctx)
+		r := HttpRequestFromContext(ctx)
+		if r == nil {
+			return "", NewInternalError()
+		}
+		return r.Header.Get("User-Agent") + " " + MethodFromContext(ctx), nil
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	response, err := Request[[]string, string](context.Background(), NewHttpClientEndpoint(srv.URL, nil), "whoami", []string{""})
+	assert.Nil(err)
+	result, err := response.Unwrap()
+	assert.Nil(err)
+	assert.Equal("Go-http-client/1.1 whoami", result)
+	assert.Nil(endpoint)
+}
@@ -13,6 +13,8 @@ var (
 	ErrInternalMethodRequired          = errors.New("method is required")
 	ErrInternalInvalidMessageStructure = errors.New("invalid message structure")
 	ErrInternalUnsupportedMessageKind  = errors.New("unsupported message kind")
+	ErrInternalInvalidParams           = errors.New("params must be array or object")
+	ErrInternalEmptyBatch              = errors.New("empty batch")
 	ErrEmptyResponse                   = errors.New("empty response")
 )
 
@@ -48,6 +50,7 @@ const (
 	InternalErrorKind  ErrorKind = "Internal error"
 	UnknownErrorKind   ErrorKind = "Unknown error"
 	ServerErrorKind    ErrorKind = "Server error"
+	RequestTimeoutKind ErrorKind = "Request timeout"
 )
 
 type Error struct {
@@ -118,7 +121,7 @@ func NewUnknownErrorWithData[T any](data T) *Error {
 
 // code has to be in range -32099 to -32000
 func NewServerError(code int) *Error {
-	return &Error{Kind: InternalErrorKind}
+	return &Error{Kind: ServerErrorKind, code: code}
 }
 
 func NewServerErrorWithData[T any](code int, data T) *Error {
@@ -127,6 +130,11 @@ func NewServerErrorWithData[T any](code int, data T) *Error {
 	return result
 }
 
+// code has to be in range -32099 to -32000
+func NewRequestTimeout(code int) *Error {
+	return &Error{Kind: RequestTimeoutKind, code: code}
+}
+
 func (e *Error) Error() string {
 	return strings.ToLower(string(e.Kind))
 }
@@ -5,11 +5,11 @@ go 1.24
 require (
 	github.com/google/uuid v1.6.0
 	github.com/stretchr/testify v1.8.1
+	nhooyr.io/websocket v1.8.11
 )
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
-	nhooyr.io/websocket v1.8.11 // indirect
 )
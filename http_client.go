@@ -12,9 +12,11 @@ import (
 
 type HttpClientEndpoint struct {
 	*http.Client
+	clientConfig
+	protocolConfig
 
 	pendingMutex sync.Mutex
-	pending      map[interface{}]chan message
+	pending      map[string]chan Message
 
 	url    string
 	logger *slog.Logger
@@ -29,7 +31,7 @@ func NewHttpClientEndpoint(baseUrl string, client *http.Client) *HttpClientEndpo
 		Client:       client,
 		url:          baseUrl,
 		pendingMutex: sync.Mutex{},
-		pending:      make(map[interface{}]chan message, 1),
+		pending:      make(map[string]chan Message, 1),
 		logger:       slog.Default(),
 	}
 }
@@ -50,22 +52,22 @@ func (c *HttpClientEndpoint) IsClosed() bool {
 	return false
 }
 
-func (c *HttpClientEndpoint) RegisterPendingRequest(requestID interface{}) <-chan message {
-	responseChan := make(chan message, 1)
+func (c *HttpClientEndpoint) RegisterPendingRequest(requestID interface{}) <-chan Message {
+	responseChan := make(chan Message, 1)
 	c.pendingMutex.Lock()
 	defer c.pendingMutex.Unlock()
-	c.pending[requestID] = responseChan
+	c.pending[idKey(requestID)] = responseChan
 	return responseChan
 }
 
 func (c *HttpClientEndpoint) UnregisterPendingRequest(requestID interface{}) {
 	c.pendingMutex.Lock()
 	defer c.pendingMutex.Unlock()
-	delete(c.pending, requestID)
+	delete(c.pending, idKey(requestID))
 }
 
 func (c *HttpClientEndpoint) WriteObject(object interface{}) error {
-	requestBody, err := json.Marshal(object)
+	requestBody, err := c.encodeObject(object)
 	if err != nil {
 		return err
 	}
@@ -101,6 +103,9 @@ func (c *HttpClientEndpoint) WriteObject(object interface{}) error {
 	if err != nil {
 		return err
 	}
+	if c.isLegacy() {
+		rpcObj.upgradeLegacy()
+	}
 	messages := rpcObj.GetMessages()
 	for _, rpcMsg := range messages {
 		kind, err := rpcMsg.GetKind()
@@ -113,7 +118,7 @@ func (c *HttpClientEndpoint) WriteObject(object interface{}) error {
 			fallthrough
 		case ERROR_RESPONSE_KIND:
 			// this is just shim to allow make common methods callable on http client
-			pendingChannel, ok := c.pending[rpcMsg.Id]
+			pendingChannel, ok := c.pending[idKey(rpcMsg.Id)]
 			if !ok {
 				c.logger.Debug("jsonrpc2: ignoring response with no corresponding request", "response_id", rpcMsg.Id)
 				continue
@@ -15,11 +15,25 @@ func writeJsonResponse(w http.ResponseWriter, response []byte, statusCode int) {
 	w.Write(response)
 }
 
+// writeResponse writes JSON-RPC 2.0 response converted to protocol version of the mux
+func (mux *ServerMux) writeResponse(w http.ResponseWriter, response []byte, statusCode int) {
+	if mux.isLegacy() {
+		if legacyResponse, err := toLegacyJson(response); err == nil {
+			response = legacyResponse
+		}
+	}
+	writeJsonResponse(w, response, statusCode)
+}
+
 type EndpointRegistry map[string]RpcMethodRegistry
 
 type ServerMux struct {
 	http.ServeMux
-	endpoints EndpointRegistry
+	serverConfig
+	protocolConfig
+	endpoints   EndpointRegistry
+	middlewares map[string][]RpcMiddleware
+	discovery   *OpenRpcInfo
 
 	logger *slog.Logger
 }
@@ -29,13 +43,17 @@ func (mux *ServerMux) RegisterEndpoint(path string) {
 		return
 	}
 	mux.HandleFunc(path, createHandler(mux, path))
+	if mux.discovery != nil {
+		RegisterDiscoverMethod(mux.endpoints[path], *mux.discovery)
+	}
 }
 
 func NewServerMux() *ServerMux {
 	result := &ServerMux{
-		ServeMux:  http.ServeMux{},
-		endpoints: make(EndpointRegistry, 1),
-		logger:    slog.Default(),
+		ServeMux:    http.ServeMux{},
+		endpoints:   make(EndpointRegistry, 1),
+		middlewares: make(map[string][]RpcMiddleware),
+		logger:      slog.Default(),
 	}
 
 	result.RegisterEndpoint("/")
@@ -58,9 +76,37 @@ func (mux *ServerMux) GetEndpoints() EndpointRegistry {
 	return mux.endpoints
 }
 
-func RegisterServerMuxEndpointMethod[TParam Params, TResult Result](mux *ServerMux, endpoint string, method string, handler RpcMethod[TParam, TResult]) {
+// UseDiscovery registers rpc.discover method on every endpoint, including endpoints registered later.
+// Each endpoint describes only its own methods.
+func (mux *ServerMux) UseDiscovery(info OpenRpcInfo) {
+	mux.discovery = &info
+	for _, reg := range mux.endpoints {
+		RegisterDiscoverMethod(reg, info)
+	}
+}
+
+// UseMiddleware wraps methods of all endpoints in middlewares. First middleware is the outermost one.
+func (mux *ServerMux) UseMiddleware(middlewares ...RpcMiddleware) {
+	mux.middlewares[""] = append(mux.middlewares[""], middlewares...)
+}
+
+// UseEndpointMiddleware wraps methods of the endpoint in middlewares. These run inside
+// middlewares registered with UseMiddleware.
+func (mux *ServerMux) UseEndpointMiddleware(endpoint string, middlewares ...RpcMiddleware) {
 	mux.RegisterEndpoint(endpoint)
-	RegisterMethod(mux.endpoints[endpoint], method, handler)
+	mux.middlewares[endpoint] = append(mux.middlewares[endpoint], middlewares...)
+}
+
+func (mux *ServerMux) getMiddlewares(path string) []RpcMiddleware {
+	global, endpoint := mux.middlewares[""], mux.middlewares[path]
+	middlewares := make([]RpcMiddleware, 0, len(global)+len(endpoint))
+	middlewares = append(middlewares, global...)
+	return append(middlewares, endpoint...)
+}
+
+func RegisterServerMuxEndpointMethod[TParam Params, TResult Result](mux *ServerMux, endpoint string, method string, handler RpcMethod[TParam, TResult], opts ...MethodOption) {
+	mux.RegisterEndpoint(endpoint)
+	RegisterMethod(mux.endpoints[endpoint], method, handler, opts...)
 }
 
 func createHandler(mux *ServerMux, path string) func(w http.ResponseWriter, r *http.Request) {
@@ -83,14 +129,14 @@ func createHandler(mux *ServerMux, path string) func(w http.ResponseWriter, r *h
 		case "application/json":
 		default:
 			mux.logger.Debug("got request with unsupported content type", "content_type", contentType)
-			writeJsonResponse(w, NewInvalidRequestWithData(fmt.Sprintf("unsupported content type: %s", contentType)).ToResponseBytes(nil), http.StatusUnsupportedMediaType)
+			mux.writeResponse(w, NewInvalidRequestWithData(fmt.Sprintf("unsupported content type: %s", contentType)).ToResponseBytes(nil), http.StatusUnsupportedMediaType)
 			return
 		}
 
 		contentLength, err := strconv.Atoi(contentLengthHeader)
 		if contentLengthHeader == "" || err != nil {
 			mux.logger.Debug("got request with invalid content length", "content_length", contentLengthHeader)
-			writeJsonResponse(w, NewInvalidRequestWithData(fmt.Sprintf("invalid content length: %s", contentLengthHeader)).ToResponseBytes(nil), http.StatusUnsupportedMediaType)
+			mux.writeResponse(w, NewInvalidRequestWithData(fmt.Sprintf("invalid content length: %s", contentLengthHeader)).ToResponseBytes(nil), http.StatusUnsupportedMediaType)
 			return
 		}
 
@@ -99,7 +145,7 @@ func createHandler(mux *ServerMux, path string) func(w http.ResponseWriter, r *h
 		_, err = io.ReadFull(r.Body, msg)
 		if err != nil {
 			mux.logger.Debug("failed to read request body", "error", err)
-			writeJsonResponse(w, NewInvalidRequestWithData("invalid request body").ToResponseBytes(nil), http.StatusUnsupportedMediaType)
+			mux.writeResponse(w, NewInvalidRequestWithData("invalid request body").ToResponseBytes(nil), http.StatusUnsupportedMediaType)
 			return
 		}
 
@@ -107,26 +153,39 @@ func createHandler(mux *ServerMux, path string) func(w http.ResponseWriter, r *h
 		err = json.Unmarshal(msg, &rpcObj)
 		if err != nil {
 			mux.logger.Debug("failed to parse request body", "error", err)
-			writeJsonResponse(w, NewParseErrorWithData(err.Error()).ToResponseBytes(nil), http.StatusUnsupportedMediaType)
+			mux.writeResponse(w, NewParseErrorWithData(err.Error()).ToResponseBytes(nil), http.StatusUnsupportedMediaType)
+			return
+		}
+
+		if mux.isLegacy() {
+			rpcObj.upgradeLegacy()
+		}
+		if rpcObj.IsEmptyBatch() {
+			mux.logger.Debug("got empty batch")
+			if mux.isStrict() {
+				mux.writeResponse(w, NewInvalidRequestWithData(ErrInternalEmptyBatch.Error()).ToResponseBytes(nil), http.StatusBadRequest)
+			}
 			return
 		}
 
+		ctx := context.WithValue(r.Context(), httpRequestContextKey, r)
+		middlewares := mux.getMiddlewares(path)
 		messages := rpcObj.GetMessages()
 		results := make([]interface{}, 0, len(messages))
 		for _, rpcMsg := range messages {
-			kind, err := rpcMsg.GetKind()
+			kind, err := rpcMsg.getKind(mux.isStrict())
 			switch kind {
 			case REQUEST_KIND:
-				results = append(results, ProcessRpcRequest(context.Background(), reg, &rpcMsg))
+				results = append(results, processRpcRequest(ctx, reg, &rpcMsg, middlewares, &mux.serverConfig, mux.logger))
 			case NOTIFICATION_KIND:
-				_ = ProcessRpcRequest(context.Background(), reg, &rpcMsg)
+				_ = processRpcRequest(ctx, reg, &rpcMsg, middlewares, &mux.serverConfig, mux.logger)
 			case SUCCESS_RESPONSE_KIND:
 				fallthrough
 			case ERROR_RESPONSE_KIND:
 				mux.logger.Debug("ignoring response message", "message", rpcMsg)
 			default:
-				mux.logger.Debug("invalid message", "message", rpcMsg)
-				results = append(results, NewInvalidRequestWithData(err.Error()).ToResponse(rpcMsg.Id))
+				mux.logger.Debug("invalid message", "message", rpcMsg, "error", err)
+				results = append(results, invalidMessageResponse(&rpcMsg, err))
 			}
 		}
 
@@ -146,13 +205,13 @@ func createHandler(mux *ServerMux, path string) func(w http.ResponseWriter, r *h
 			responseBody, err := json.Marshal(nonEmptyResults[0])
 			if err != nil {
 				mux.logger.Debug("failed to marshal response", "error", err)
-				writeJsonResponse(w, NewInternalErrorWithData(fmt.Sprintf("failed to marshal response: %s", err.Error())).ToResponseBytes(nil), http.StatusInternalServerError)
+				mux.writeResponse(w, NewInternalErrorWithData(fmt.Sprintf("failed to marshal response: %s", err.Error())).ToResponseBytes(nil), http.StatusInternalServerError)
 				return
 			}
 			if _, isErrorResponse := nonEmptyResults[0].(*errorResponse); isErrorResponse {
-				writeJsonResponse(w, responseBody, http.StatusBadRequest)
+				mux.writeResponse(w, responseBody, http.StatusBadRequest)
 			} else {
-				writeJsonResponse(w, responseBody, http.StatusOK)
+				mux.writeResponse(w, responseBody, http.StatusOK)
 			}
 			return
 		}
@@ -160,12 +219,12 @@ func createHandler(mux *ServerMux, path string) func(w http.ResponseWriter, r *h
 		responseBody, err := json.Marshal(nonEmptyResults)
 		if err != nil {
 			mux.logger.Debug("failed to marshal response", "error", err)
-			writeJsonResponse(w, NewInternalErrorWithData(err.Error()).ToResponseBytes(nil), http.StatusInternalServerError)
+			mux.writeResponse(w, NewInternalErrorWithData(err.Error()).ToResponseBytes(nil), http.StatusInternalServerError)
 			return
 		}
 		// there is no information in the spec about how to handle multiple responses
 		// whether to return any other status code than 200 if there is error in one of the responses or all of them
 		// so we just return 200 and let the client handle the responses
-		writeJsonResponse(w, responseBody, http.StatusOK)
+		mux.writeResponse(w, responseBody, http.StatusOK)
 	}
 }
@@ -89,12 +89,12 @@ func TestHttpRequest(t *testing.T) {
 	go s.ListenAndServe()
 	defer s.Close()
 	time.Sleep(1 * time.Second)
-	r, e := Request[string, string](context.Background(), c1, "hello", "World")
+	r, e := Request[[]string, string](context.Background(), c1, "hello", []string{"World"})
 	assert.Nil(e)
 	result, e := r.Unwrap()
 	assert.Nil(e)
 	assert.Equal("Hello World", result)
-	r, e = Request[string, string](context.Background(), c2, "bye", "World")
+	r, e = Request[[]string, string](context.Background(), c2, "bye", []string{"World"})
 	assert.Nil(e)
 	result, e = r.Unwrap()
 	assert.Nil(e)
@@ -120,9 +120,9 @@ func TestHttpBatchRequest(t *testing.T) {
 	go s.ListenAndServe()
 	defer s.Close()
 	time.Sleep(3 * time.Second)
-	rs, e := Batch[string, string](context.Background(), c1, []RequestInfo[string]{
-		{"hello", "World", false},
-		{"bye", "World", false},
+	rs, e := Batch[[]string, string](context.Background(), c1, []RequestInfo[[]string]{
+		{"hello", []string{"World"}, false},
+		{"bye", []string{"World"}, false},
 	})
 	assert.Nil(e)
 	assert.Equal(2, len(rs))
@@ -156,9 +156,9 @@ func TestHttpNotification(t *testing.T) {
 	go s.ListenAndServe()
 	defer s.Close()
 	time.Sleep(1 * time.Second)
-	e := Notify(context.Background(), c1, "hello", "World")
+	e := Notify(context.Background(), c1, "hello", []string{"World"})
 	assert.Nil(e)
-	e = Notify(context.Background(), c2, "bye", "World")
+	e = Notify(context.Background(), c2, "bye", []string{"World"})
 	assert.Nil(e)
 	select {
 	case <-signaled:
@@ -0,0 +1,68 @@
+package jsonrpc2
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// ID is id of received message preserving its JSON representation. Numbers are kept
+// as json.Number so large integers are not rounded and are echoed back exactly.
+type ID struct {
+	// string, json.Number or value of invalid id
+	value interface{}
+}
+
+func (id *ID) UnmarshalJSON(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(&id.value)
+}
+
+func (id ID) MarshalJSON() ([]byte, error) {
+	return json.Marshal(id.value)
+}
+
+// IsNull reports whether the id is null. Nil *ID stands for absent id.
+func (id *ID) IsNull() bool {
+	return id != nil && id.value == nil
+}
+
+// IsString reports whether the id is string
+func (id *ID) IsString() bool {
+	_, ok := id.value.(string)
+	return ok
+}
+
+// IsNumber reports whether the id is number
+func (id *ID) IsNumber() bool {
+	_, ok := id.value.(json.Number)
+	return ok
+}
+
+// isValid reports whether the id is string or number
+func (id *ID) isValid() bool {
+	return id != nil && (id.IsString() || id.IsNumber())
+}
+
+// Value returns string ids as string, integers representable as int64 as int64 and other numbers as json.Number.
+// Returns nil if id is nil.
+func (id *ID) Value() interface{} {
+	if id == nil {
+		return nil
+	}
+	if number, ok := id.value.(json.Number); ok {
+		if n, err := strconv.ParseInt(number.String(), 10, 64); err == nil {
+			return n
+		}
+	}
+	return id.value
+}
+
+func (id ID) String() string {
+	if number, ok := id.value.(json.Number); ok {
+		return number.String()
+	}
+	return fmt.Sprint(id.value)
+}
@@ -0,0 +1,121 @@
+package jsonrpc2
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIDRoundTrip(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		raw   string
+		value interface{}
+	}{
+		{`1`, int64(1)},
+		{`-42`, int64(-42)},
+		{`"1"`, "1"},
+		{`""`, ""},
+		{`12345678901234567890`, json.Number("12345678901234567890")},
+		{`1.5`, json.Number("1.5")},
+	}
+	for _, test := range tests {
+		var id ID
+		assert.Nil(json.Unmarshal([]byte(test.raw), &id))
+		assert.Equal(test.value, id.Value(), test.raw)
+		assert.True(id.isValid())
+		data, err := json.Marshal(&id)
+		assert.Nil(err)
+		assert.Equal(test.raw, string(data))
+	}
+
+	var id ID
+	assert.Nil(json.Unmarshal([]byte(`{"a": 1}`), &id))
+	assert.False(id.isValid())
+	assert.Nil((*ID)(nil).Value())
+}
+
+func TestIDKey(t *testing.T) {
+	assert := assert.New(t)
+	var number, str ID
+	assert.Nil(json.Unmarshal([]byte(`7`), &number))
+	assert.Nil(json.Unmarshal([]byte(`"7"`), &str))
+	assert.Equal(idKey(7), idKey(&number))
+	assert.Equal(idKey(uint8(7)), idKey(&number))
+	assert.Equal(idKey("7"), idKey(&str))
+	assert.NotEqual(idKey(&number), idKey(&str))
+}
+
+func processRawRequest(reg RpcMethodRegistry, raw string) (string, error) {
+	var rpcMsg Message
+	if err := json.Unmarshal([]byte(raw), &rpcMsg); err != nil {
+		return "", err
+	}
+	response := ProcessRpcRequest(context.Background(), reg, &rpcMsg)
+	if response == nil {
+		return "", nil
+	}
+	data, err := json.Marshal(response)
+	return string(data), err
+}
+
+func TestRequestIdEchoed(t *testing.T) {
+	assert := assert.New(t)
+	reg := NewMethodRegistry()
+	RegisterMethod(reg, "id", requestIdOf)
+
+	for _, id := range []string{`1`, `"1"`, `12345678901234567890`, `-0`} {
+		response, err := processRawRequest(reg, `{"jsonrpc": "2.0", "id": `+id+`, "method": "id"}`)
+		assert.Nil(err)
+		assert.Contains(response, `"id":`+id+`,`)
+	}
+}
+
+func TestNullId(t *testing.T) {
+	assert := assert.New(t)
+	var rpcMsg Message
+	assert.Nil(json.Unmarshal([]byte(`{"jsonrpc": "2.0", "id": null, "method": "id"}`), &rpcMsg))
+	assert.True(rpcMsg.Id.IsNull())
+	kind, err := rpcMsg.GetKind()
+	assert.Nil(err)
+	assert.Equal(REQUEST_KIND, kind)
+	// lenient mode treats request with null id as notification
+	kind, err = rpcMsg.getKind(false)
+	assert.Nil(err)
+	assert.Equal(NOTIFICATION_KIND, kind)
+
+	rpcMsg = Message{}
+	assert.Nil(json.Unmarshal([]byte(`{"jsonrpc": "2.0", "id": null, "result": 1}`), &rpcMsg))
+	_, err = rpcMsg.GetKind()
+	assert.NotNil(err)
+
+	response, err := json.Marshal(NewInvalidRequest().ToResponse(nil))
+	assert.Nil(err)
+	assert.Contains(string(response), `"id":null`)
+}
+
+func TestStreamNumericIds(t *testing.T) {
+	assert := assert.New(t)
+	connA, connB := net.Pipe()
+	s := NewStreamEndpoint(context.Background(), NewPlainObjectStream(connA))
+	c := NewStreamEndpoint(context.Background(), NewPlainObjectStream(connB))
+	RegisterEndpointMethod(s, "id", requestIdOf)
+
+	response, err := RequestWithId[int, []string, string](context.Background(), c, 7, "id", []string{""})
+	assert.Nil(err)
+	assert.Equal("7", response.Result)
+	assert.Equal(int64(7), response.Id)
+
+	response, err = RequestWithId[uint64, []string, string](context.Background(), c, 12345678901234567890, "id", []string{""})
+	assert.Nil(err)
+	assert.Equal("12345678901234567890", response.Result)
+	assert.Equal(json.Number("12345678901234567890"), response.Id)
+
+	response, err = RequestWithId[string, []string, string](context.Background(), c, "7", "id", []string{""})
+	assert.Nil(err)
+	assert.Equal("7", response.Result)
+	assert.Equal("7", response.Id)
+}
@@ -0,0 +1,82 @@
+package jsonrpc2
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/google/uuid"
+)
+
+// IdGenerator generates ids of requests sent by Request, Batch, Go and BatchBuilder.
+// Generated id has to be string or integer and unique among requests pending on the endpoint.
+type IdGenerator interface {
+	NextId() (interface{}, error)
+}
+
+// IdGeneratorFunc adapts function to IdGenerator
+type IdGeneratorFunc func() (interface{}, error)
+
+func (f IdGeneratorFunc) NextId() (interface{}, error) {
+	return f()
+}
+
+type sequentialIdGenerator struct {
+	last atomic.Int64
+}
+
+// SequentialIds generates int64 ids 1, 2, 3...
+func SequentialIds() IdGenerator {
+	return &sequentialIdGenerator{}
+}
+
+func (g *sequentialIdGenerator) NextId() (interface{}, error) {
+	return g.last.Add(1), nil
+}
+
+// UUIDv4Ids generates random UUID strings, it is the default generator
+func UUIDv4Ids() IdGenerator {
+	return IdGeneratorFunc(func() (interface{}, error) {
+		id, err := uuid.NewRandom()
+		if err != nil {
+			return nil, err
+		}
+		return id.String(), nil
+	})
+}
+
+// UUIDv7Ids generates time ordered UUID strings
+func UUIDv7Ids() IdGenerator {
+	return IdGeneratorFunc(func() (interface{}, error) {
+		id, err := uuid.NewV7()
+		if err != nil {
+			return nil, err
+		}
+		return id.String(), nil
+	})
+}
+
+// PrefixedIds generates string ids consisting of prefix and id generated by generator, e.g. "client-1"
+func PrefixedIds(prefix string, generator IdGenerator) IdGenerator {
+	return IdGeneratorFunc(func() (interface{}, error) {
+		id, err := generator.NextId()
+		if err != nil {
+			return nil, err
+		}
+		return fmt.Sprintf("%s%v", prefix, id), nil
+	})
+}
+
+var defaultIdGenerator = UUIDv4Ids()
+
+// UseIdGenerator sets generator of ids of requests sent through the endpoint, UUIDv4Ids if nil
+func (c *clientConfig) UseIdGenerator(generator IdGenerator) {
+	c.idGenerator = generator
+}
+
+// newRequestId generates id of request sent through the endpoint
+func newRequestId(c EndpointClient) (interface{}, error) {
+	if generator := getClientConfig(c).idGenerator; generator != nil {
+		return generator.NextId()
+	}
+	return defaultIdGenerator.NextId()
+}
@@ -0,0 +1,90 @@
+package jsonrpc2
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func requestIdOf(ctx context.Context, _ string) (string, *Error) {
+	return fmt.Sprint(RequestIdFromContext(ctx)), nil
+}
+
+func TestIdGenerators(t *testing.T) {
+	assert := assert.New(t)
+	sequential := SequentialIds()
+	for i := int64(1); i <= 3; i++ {
+		id, err := sequential.NextId()
+		assert.Nil(err)
+		assert.Equal(i, id)
+	}
+
+	id, err := UUIDv4Ids().NextId()
+	assert.Nil(err)
+	parsed, err := uuid.Parse(id.(string))
+	assert.Nil(err)
+	assert.Equal(uuid.Version(4), parsed.Version())
+
+	id, err = UUIDv7Ids().NextId()
+	assert.Nil(err)
+	parsed, err = uuid.Parse(id.(string))
+	assert.Nil(err)
+	assert.Equal(uuid.Version(7), parsed.Version())
+
+	prefixed := PrefixedIds("client-", SequentialIds())
+	id, err = prefixed.NextId()
+	assert.Nil(err)
+	assert.Equal("client-1", id)
+}
+
+func TestStreamSequentialIds(t *testing.T) {
+	assert := assert.New(t)
+	connA, connB := net.Pipe()
+	s := NewStreamEndpoint(context.Background(), NewPlainObjectStream(connA))
+	c := NewStreamEndpoint(context.Background(), NewPlainObjectStream(connB))
+	c.UseIdGenerator(SequentialIds())
+	RegisterEndpointMethod(s, "id", requestIdOf)
+
+	for _, expected := range []string{"1", "2"} {
+		response, err := Request[[]string, string](context.Background(), c, "id", []string{""})
+		assert.Nil(err)
+		assert.Equal(expected, response.Result)
+	}
+
+	responses, err := Batch[[]string, string](context.Background(), c, []RequestInfo[[]string]{{Method: "id"}, {Method: "id"}})
+	assert.Nil(err)
+	assert.Equal("3", responses[0].Result)
+	assert.Equal("4", responses[1].Result)
+
+	response, err := Go[[]string, string](context.Background(), c, "id", []string{""}).Wait(context.Background())
+	assert.Nil(err)
+	assert.Equal("5", response.Result)
+}
+
+func TestRequestWithId(t *testing.T) {
+	assert := assert.New(t)
+	mux := NewServerMux()
+	RegisterServerMuxEndpointMethod(mux, "/", "id", requestIdOf)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	c := NewHttpClientEndpoint(srv.URL, nil)
+
+	response, err := RequestWithId[int, []string, string](context.Background(), c, 42, "id", []string{""})
+	assert.Nil(err)
+	assert.Equal("42", response.Result)
+
+	response, err = RequestWithId[string, []string, string](context.Background(), c, "custom", "id", []string{""})
+	assert.Nil(err)
+	assert.Equal("custom", response.Result)
+	assert.Equal("custom", response.Id)
+
+	c.UseIdGenerator(PrefixedIds("http-", SequentialIds()))
+	response, err = Request[[]string, string](context.Background(), c, "id", []string{""})
+	assert.Nil(err)
+	assert.Equal("http-1", response.Result)
+}
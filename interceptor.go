@@ -0,0 +1,64 @@
+package jsonrpc2
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// ClientCall describes outgoing request or notification.
+type ClientCall struct {
+	Id     interface{}
+	Method string
+	Params interface{}
+	// Meta is sent along with the request in the "meta" member
+	Meta           map[string]interface{}
+	IsNotification bool
+}
+
+// ClientInvoker sends calls to the remote endpoint. Responses are returned in the order of calls,
+// notifications have no response.
+type ClientInvoker func(ctx context.Context, calls []*ClientCall) ([]*Response[json.RawMessage], error)
+
+// ClientInterceptor intercepts calls made by Request, Notify and Batch. It can modify calls
+// before passing them to next, inspect responses, retry or short-circuit the call.
+// Single request or notification is passed as a slice with one call.
+type ClientInterceptor func(ctx context.Context, calls []*ClientCall, next ClientInvoker) ([]*Response[json.RawMessage], error)
+
+// ChainClientInterceptors wraps invoker in interceptors. First interceptor is the outermost one.
+func ChainClientInterceptors(invoker ClientInvoker, interceptors ...ClientInterceptor) ClientInvoker {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], invoker
+		invoker = func(ctx context.Context, calls []*ClientCall) ([]*Response[json.RawMessage], error) {
+			return interceptor(ctx, calls, next)
+		}
+	}
+	return invoker
+}
+
+// clientConfig holds client side configuration shared by client endpoints
+type clientConfig struct {
+	interceptors []ClientInterceptor
+	// method of notification sent when request context is cancelled, empty if disabled
+	cancelMethod string
+	idGenerator  IdGenerator
+}
+
+// UseInterceptors appends interceptors applied to Request, Notify and Batch calls made through the endpoint.
+func (c *clientConfig) UseInterceptors(interceptors ...ClientInterceptor) {
+	c.interceptors = append(c.interceptors, interceptors...)
+}
+
+func (c *clientConfig) getClientConfig() *clientConfig {
+	return c
+}
+
+type configurableClient interface {
+	getClientConfig() *clientConfig
+}
+
+func getClientConfig(c EndpointClient) *clientConfig {
+	if client, ok := c.(configurableClient); ok {
+		return client.getClientConfig()
+	}
+	return &clientConfig{}
+}
@@ -0,0 +1,125 @@
+package jsonrpc2
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamClientInterceptors(t *testing.T) {
+	assert := assert.New(t)
+	connA, connB := net.Pipe()
+	s := NewStreamEndpoint(context.Background(), NewPlainObjectStream(connA))
+	c := NewStreamEndpoint(context.Background(), NewPlainObjectStream(connB))
+	RegisterEndpointMethod(s, "v2.hello", func(ctx context.Context, name string) (string, *Error) {
+		return "Hello " + name, nil
+	})
+
+	observed := []string{}
+	c.UseInterceptors(
+		func(ctx context.Context, calls []*ClientCall, next ClientInvoker) ([]*Response[json.RawMessage], error) {
+			responses, err := next(ctx, calls)
+			for _, response := range responses {
+				observed = append(observed, string(response.Result))
+			}
+			return responses, err
+		},
+		func(ctx context.Context, calls []*ClientCall, next ClientInvoker) ([]*Response[json.RawMessage], error) {
+			for _, call := range calls {
+				call.Method = "v2." + call.Method
+			}
+			return next(ctx, calls)
+		},
+	)
+
+	response, err := Request[[]string, string](context.Background(), c, "hello", []string{"World"})
+	assert.Nil(err)
+	result, err := response.Unwrap()
+	assert.Nil(err)
+	assert.Equal("Hello World", result)
+
+	responses, err := Batch[[]string, string](context.Background(), c, []RequestInfo[[]string]{
+		{Method: "hello", Params: []string{"A"}},
+		{Method: "hello", Params: []string{"B"}},
+	})
+	assert.Nil(err)
+	assert.Len(responses, 2)
+	assert.Equal([]string{"\"Hello World\"", "\"Hello A\"", "\"Hello B\""}, observed)
+}
+
+func TestClientInterceptorMeta(t *testing.T) {
+	assert := assert.New(t)
+	connA, connB := net.Pipe()
+	s := NewStreamEndpoint(context.Background(), NewPlainObjectStream(connA))
+	c := NewStreamEndpoint(context.Background(), NewPlainObjectStream(connB))
+	received := make(chan string, 1)
+	s.UseMiddleware(func(next RpcHandler) RpcHandler {
+		return func(ctx context.Context, rpcMsg *Message) interface{} {
+			received <- string(rpcMsg.Meta)
+			return next(ctx, rpcMsg)
+		}
+	})
+	RegisterEndpointMethod(s, "hello", func(ctx context.Context, name string) (string, *Error) {
+		return "Hello " + name, nil
+	})
+	c.UseInterceptors(func(ctx context.Context, calls []*ClientCall, next ClientInvoker) ([]*Response[json.RawMessage], error) {
+		for _, call := range calls {
+			call.Meta = map[string]interface{}{"trace": "abc"}
+		}
+		return next(ctx, calls)
+	})
+
+	assert.Nil(Notify(context.Background(), c, "hello", []string{"World"}))
+	assert.Equal(`{"trace":"abc"}`, <-received)
+}
+
+func TestHttpClientInterceptorRetry(t *testing.T) {
+	assert := assert.New(t)
+	mux := NewServerMux()
+	attempts := 0
+	RegisterEndpointMethod(mux, "flaky", func(ctx context.Context, name string) (string, *Error) {
+		attempts++
+		if attempts < 3 {
+			return "", NewInternalError()
+		}
+		return "Hello " + name, nil
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	c := NewHttpClientEndpoint(srv.URL, nil)
+	c.UseInterceptors(func(ctx context.Context, calls []*ClientCall, next ClientInvoker) ([]*Response[json.RawMessage], error) {
+		for {
+			responses, err := next(ctx, calls)
+			if err == nil && len(responses) == 1 && responses[0].IsError() {
+				continue
+			}
+			return responses, err
+		}
+	})
+
+	response, err := Request[[]string, string](context.Background(), c, "flaky", []string{"World"})
+	assert.Nil(err)
+	result, err := response.Unwrap()
+	assert.Nil(err)
+	assert.Equal("Hello World", result)
+	assert.Equal(3, attempts)
+}
+
+func TestClientInterceptorShortCircuit(t *testing.T) {
+	assert := assert.New(t)
+	c := NewHttpClientEndpoint("http://127.0.0.1:1", nil)
+	errDenied := errors.New("denied")
+	c.UseInterceptors(func(ctx context.Context, calls []*ClientCall, next ClientInvoker) ([]*Response[json.RawMessage], error) {
+		return nil, errDenied
+	})
+
+	_, err := Request[[]string, string](context.Background(), c, "hello", []string{"World"})
+	assert.ErrorIs(err, errDenied)
+	assert.ErrorIs(Notify(context.Background(), c, "hello", []string{"World"}), errDenied)
+}
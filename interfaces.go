@@ -6,7 +6,7 @@ import (
 
 type EndpointClient interface {
 	WriteObject(obj interface{}) error
-	RegisterPendingRequest(id interface{}) <-chan message
+	RegisterPendingRequest(id interface{}) <-chan Message
 	UnregisterPendingRequest(id interface{})
 	Close() error
 	IsClosed() bool
@@ -0,0 +1,213 @@
+package jsonrpc2
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultPingMethod is method of keepalive ping requests
+const DefaultPingMethod = "$/ping"
+
+const (
+	// DefaultKeepaliveTimeout is timeout of waiting for pong if not set in KeepaliveOptions
+	DefaultKeepaliveTimeout = 10 * time.Second
+	// DefaultKeepaliveMaxMissed is number of missed pongs closing the endpoint if not set in KeepaliveOptions
+	DefaultKeepaliveMaxMissed = 3
+)
+
+// keepalivePingIdPrefix distinguishes ping requests from requests of the application
+const keepalivePingIdPrefix = "$/ping/"
+
+// pendingPingKeyPrefix is prefix of keys of pending ping requests
+var pendingPingKeyPrefix = idKey(keepalivePingIdPrefix)[:len(keepalivePingIdPrefix)+1]
+
+var (
+	ErrKeepaliveTimeout = errors.New("keepalive timeout")
+	ErrIdleTimeout      = errors.New("idle timeout")
+)
+
+// Pinger is implemented by object streams with transport level ping, e.g. websocket streams.
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// KeepaliveOptions configures keepalive of StreamEndpoint
+type KeepaliveOptions struct {
+	// Interval between pings, 0 disables pings
+	Interval time.Duration
+	// Timeout of waiting for pong, DefaultKeepaliveTimeout if 0
+	Timeout time.Duration
+	// MaxMissed is number of consecutive missed pongs after which the endpoint is closed,
+	// DefaultKeepaliveMaxMissed if 0
+	MaxMissed int
+	// Method of ping requests. If empty, streams implementing Pinger are pinged on transport
+	// level and DefaultPingMethod is used for other streams. Any response, including error
+	// response, is considered a pong.
+	Method string
+	// IdleTimeout closes the endpoint when no requests, notifications or responses other than
+	// keepalive pings are exchanged for the duration, 0 disables it. Endpoint with running handlers
+	// or requests waiting for responses is not idle.
+	IdleTimeout time.Duration
+}
+
+type keepalive struct {
+	options KeepaliveOptions
+	// method pings of the peer are answered on
+	method       string
+	codecPing    bool
+	lastActivity atomic.Int64
+	pings        atomic.Uint64
+}
+
+// UseKeepalive pings the peer periodically and closes the endpoint when pongs are missed
+// or the connection is idle. Ping requests of the peer are answered by the endpoint.
+// Cause of the close is returned by CloseCause. Should be set in ConnOpt passed to NewStreamEndpoint.
+func (c *StreamEndpoint) UseKeepalive(options KeepaliveOptions) {
+	if options.Timeout <= 0 {
+		options.Timeout = DefaultKeepaliveTimeout
+	}
+	if options.MaxMissed <= 0 {
+		options.MaxMissed = DefaultKeepaliveMaxMissed
+	}
+	k := &keepalive{options: options, method: options.Method}
+	if k.method == "" {
+		_, k.codecPing = c.stream.(Pinger)
+		k.method = DefaultPingMethod
+	}
+	k.touch()
+	c.keepalive.Store(k)
+	if options.Interval > 0 || options.IdleTimeout > 0 {
+		go c.runKeepalive(k)
+	}
+}
+
+func (k *keepalive) touch() {
+	if k != nil {
+		k.lastActivity.Store(time.Now().UnixNano())
+	}
+}
+
+func (k *keepalive) idleFor() time.Duration {
+	return time.Since(time.Unix(0, k.lastActivity.Load()))
+}
+
+// isPing reports whether the message is keepalive ping or response to it
+func (k *keepalive) isPing(rpcMsg *Message) bool {
+	if k == nil {
+		return false
+	}
+	if id, ok := rpcMsg.Id.Value().(string); ok && rpcMsg.Method == "" && strings.HasPrefix(id, keepalivePingIdPrefix) {
+		return true
+	}
+	return rpcMsg.Method == k.method
+}
+
+func (c *StreamEndpoint) runKeepalive(k *keepalive) {
+	var pingTick <-chan time.Time
+	if k.options.Interval > 0 {
+		ticker := time.NewTicker(k.options.Interval)
+		defer ticker.Stop()
+		pingTick = ticker.C
+	}
+	var idleTimer *time.Timer
+	var idleTick <-chan time.Time
+	if k.options.IdleTimeout > 0 {
+		idleTimer = time.NewTimer(k.options.IdleTimeout)
+		defer idleTimer.Stop()
+		idleTick = idleTimer.C
+	}
+
+	missed := 0
+	for {
+		select {
+		case <-c.closeNotify:
+			return
+		case <-pingTick:
+			if err := c.ping(k); err != nil {
+				missed++
+				c.logger.Debug("jsonrpc2: keepalive ping failed", "error", err, "missed", missed)
+				if missed >= k.options.MaxMissed {
+					c.close(fmt.Errorf("%w: %d pings missed", ErrKeepaliveTimeout, missed))
+					return
+				}
+				continue
+			}
+			missed = 0
+		case <-idleTick:
+			if c.isBusy() {
+				k.touch()
+			}
+			idle := k.idleFor()
+			if idle >= k.options.IdleTimeout {
+				c.close(ErrIdleTimeout)
+				return
+			}
+			idleTimer.Reset(k.options.IdleTimeout - idle)
+		}
+	}
+}
+
+// isBusy reports whether handlers are running or requests sent through the endpoint wait for responses
+func (c *StreamEndpoint) isBusy() bool {
+	c.handlersMutex.Lock()
+	handlers := c.handlers
+	c.handlersMutex.Unlock()
+	if handlers > 0 {
+		return true
+	}
+	c.pendingMutex.Lock()
+	defer c.pendingMutex.Unlock()
+	for key := range c.pending {
+		if !strings.HasPrefix(key, pendingPingKeyPrefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// ping sends ping to the peer and waits for pong
+func (c *StreamEndpoint) ping(k *keepalive) error {
+	ctx, cancel := context.WithTimeout(context.Background(), k.options.Timeout)
+	defer cancel()
+	if k.codecPing {
+		return c.stream.(Pinger).Ping(ctx)
+	}
+
+	id := fmt.Sprintf("%s%d", keepalivePingIdPrefix, k.pings.Add(1))
+	pong := c.RegisterPendingRequest(id)
+	defer c.UnregisterPendingRequest(id)
+	if err := c.writeObject(NewRequest(id, k.method, (interface{})(nil))); err != nil {
+		return err
+	}
+	select {
+	case _, ok := <-pong:
+		if !ok {
+			return ErrStreamClosed
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// answerPing responds to ping request of the peer
+func (c *StreamEndpoint) answerPing(rpcMsg Message) {
+	if rpcMsg.Id == nil {
+		return
+	}
+	if err := c.writeObject(NewSuccessResponseI(rpcMsg.Id, "pong")); err != nil {
+		c.logger.Debug("jsonrpc2: failed to answer ping", "error", err)
+	}
+}
+
+// CloseCause returns reason the endpoint was closed for, e.g. ErrKeepaliveTimeout, ErrIdleTimeout
+// or error reading from the stream. Returns nil if the endpoint is open or was closed by Close.
+func (c *StreamEndpoint) CloseCause() error {
+	c.pendingMutex.Lock()
+	defer c.pendingMutex.Unlock()
+	return c.closeCause
+}
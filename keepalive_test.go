@@ -0,0 +1,141 @@
+package jsonrpc2
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamKeepalive(t *testing.T) {
+	assert := assert.New(t)
+	connA, connB := net.Pipe()
+	s := NewStreamEndpoint(context.Background(), NewPlainObjectStream(connA), func(s *StreamEndpoint) {
+		s.UseKeepalive(KeepaliveOptions{Interval: 10 * time.Millisecond})
+	})
+	pings := make(chan struct{}, 100)
+	c := NewStreamEndpoint(context.Background(), NewPlainObjectStream(connB), func(c *StreamEndpoint) {
+		c.UseKeepalive(KeepaliveOptions{Interval: 10 * time.Millisecond})
+		c.UseMiddleware(func(next RpcHandler) RpcHandler {
+			return func(ctx context.Context, rpcMsg *Message) interface{} {
+				pings <- struct{}{}
+				return next(ctx, rpcMsg)
+			}
+		})
+	})
+
+	// both endpoints keep pinging and getting pongs
+	assert.Eventually(func() bool {
+		return s.keepalive.Load().pings.Load() >= 5 && c.keepalive.Load().pings.Load() >= 5
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.False(s.IsClosed())
+	assert.False(c.IsClosed())
+	// pings are answered by the endpoint, not passed to handlers
+	assert.Len(pings, 0)
+	assert.Nil(s.CloseCause())
+}
+
+func TestStreamKeepaliveMissedPongs(t *testing.T) {
+	assert := assert.New(t)
+	connA, connB := net.Pipe()
+	c := NewStreamEndpoint(context.Background(), NewPlainObjectStream(connA), func(c *StreamEndpoint) {
+		c.UseKeepalive(KeepaliveOptions{Interval: 10 * time.Millisecond, Timeout: 10 * time.Millisecond, MaxMissed: 2})
+	})
+	// peer reads everything and never responds
+	go func() {
+		decoder := json.NewDecoder(connB)
+		for {
+			var obj json.RawMessage
+			if err := decoder.Decode(&obj); err != nil {
+				return
+			}
+		}
+	}()
+
+	select {
+	case <-c.GetOnCloseListener():
+	case <-time.After(5 * time.Second):
+		t.Fatal("endpoint not closed")
+	}
+	assert.ErrorIs(c.CloseCause(), ErrKeepaliveTimeout)
+}
+
+func TestStreamIdleTimeout(t *testing.T) {
+	assert := assert.New(t)
+	connA, connB := net.Pipe()
+	s := NewStreamEndpoint(context.Background(), NewPlainObjectStream(connA), func(s *StreamEndpoint) {
+		RegisterEndpointMethod(s, "hello", func(ctx context.Context, name string) (string, *Error) {
+			return "Hello " + name, nil
+		})
+	})
+	c := NewStreamEndpoint(context.Background(), NewPlainObjectStream(connB), func(c *StreamEndpoint) {
+		c.UseKeepalive(KeepaliveOptions{Interval: 10 * time.Millisecond, IdleTimeout: 250 * time.Millisecond})
+	})
+
+	// requests sent more often than idle timeout keep the endpoint open even though
+	// pings alone would not
+	for i := 0; i < 20; i++ {
+		_, err := Request[[]string, string](context.Background(), c, "hello", []string{"World"})
+		assert.Nil(err)
+		time.Sleep(25 * time.Millisecond)
+	}
+	assert.False(c.IsClosed())
+
+	select {
+	case <-c.GetOnCloseListener():
+	case <-time.After(5 * time.Second):
+		t.Fatal("endpoint not closed")
+	}
+	assert.ErrorIs(c.CloseCause(), ErrIdleTimeout)
+	s.Wait()
+	assert.True(s.IsClosed())
+}
+
+func TestStreamIdleTimeoutBusy(t *testing.T) {
+	assert := assert.New(t)
+	connA, connB := net.Pipe()
+	s := NewStreamEndpoint(context.Background(), NewPlainObjectStream(connA), func(s *StreamEndpoint) {
+		s.UseKeepalive(KeepaliveOptions{IdleTimeout: 100 * time.Millisecond})
+		RegisterEndpointMethod(s, "slow", func(ctx context.Context, name string) (string, *Error) {
+			time.Sleep(300 * time.Millisecond)
+			return "Hello " + name, nil
+		})
+	})
+	c := NewStreamEndpoint(context.Background(), NewPlainObjectStream(connB), func(c *StreamEndpoint) {
+		c.UseKeepalive(KeepaliveOptions{IdleTimeout: 100 * time.Millisecond})
+	})
+
+	// neither the server running the handler nor the client waiting for the response is idle
+	response, err := Request[[]string, string](context.Background(), c, "slow", []string{"World"})
+	assert.Nil(err)
+	assert.Equal("Hello World", response.Result)
+
+	select {
+	case <-s.GetOnCloseListener():
+	case <-time.After(5 * time.Second):
+		t.Fatal("endpoint not closed")
+	}
+	c.Wait()
+	assert.True(errors.Is(s.CloseCause(), ErrIdleTimeout) || errors.Is(c.CloseCause(), ErrIdleTimeout))
+}
+
+func TestWebSocketKeepalive(t *testing.T) {
+	assert := assert.New(t)
+	handler := NewWebSocketHandler(nil)
+	srv, url := createWebSocketServer(handler)
+	defer srv.Close()
+
+	c, err := DialWebSocket(context.Background(), url, nil, func(c *StreamEndpoint) {
+		c.UseKeepalive(KeepaliveOptions{Interval: 10 * time.Millisecond})
+	})
+	assert.Nil(err)
+	defer c.Close()
+	assert.True(c.keepalive.Load().codecPing)
+
+	time.Sleep(100 * time.Millisecond)
+	assert.False(c.IsClosed())
+}
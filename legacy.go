@@ -0,0 +1,96 @@
+package jsonrpc2
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// ProtocolVersion is version of JSON-RPC messages sent by the endpoint
+type ProtocolVersion int
+
+const (
+	// JsonRpc20 is the default protocol version
+	JsonRpc20 ProtocolVersion = iota
+	// JsonRpc10 sends messages without jsonrpc member, notifications with null id and responses
+	// with both result and error members, one of them null. Received messages of both versions are accepted.
+	JsonRpc10
+)
+
+var jsonNull = json.RawMessage("null")
+
+// UseProtocolVersion sets version of messages sent by the endpoint. Should be set before
+// the first message is sent or received, stream endpoints are configured in ConnOpt passed
+// to NewStreamEndpoint.
+func (c *protocolConfig) UseProtocolVersion(version ProtocolVersion) {
+	c.version = version
+}
+
+func (c *protocolConfig) isLegacy() bool {
+	return c.version == JsonRpc10
+}
+
+// encodeObject marshals object written by the endpoint in its protocol version
+func (c *protocolConfig) encodeObject(obj interface{}) (json.RawMessage, error) {
+	data, err := json.Marshal(obj)
+	if err != nil || !c.isLegacy() {
+		return data, err
+	}
+	return toLegacyJson(data)
+}
+
+// upgradeLegacy converts JSON-RPC 1.0 messages of the object to JSON-RPC 2.0 ones
+func (r *Object) upgradeLegacy() {
+	for i := range r.messages {
+		r.messages[i].upgradeLegacy()
+	}
+}
+
+func (r *Message) upgradeLegacy() {
+	if r.invalid != nil {
+		return
+	}
+	if r.Version == "" {
+		r.Version = jsonRpcVersion
+	}
+	if r.IsRequest() && r.Id.IsNull() {
+		r.Id = nil
+	}
+	if r.Error != nil && bytes.Equal(bytes.TrimSpace(r.Result), jsonNull) {
+		r.Result = nil
+	}
+}
+
+// toLegacyJson converts JSON-RPC 2.0 message or batch to JSON-RPC 1.0
+func toLegacyJson(data []byte) ([]byte, error) {
+	if isJsonArray(data) {
+		var objects []map[string]json.RawMessage
+		if err := json.Unmarshal(data, &objects); err != nil {
+			return nil, err
+		}
+		for _, object := range objects {
+			toLegacyMessage(object)
+		}
+		return json.Marshal(objects)
+	}
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, err
+	}
+	toLegacyMessage(object)
+	return json.Marshal(object)
+}
+
+func toLegacyMessage(object map[string]json.RawMessage) {
+	delete(object, "jsonrpc")
+	if _, ok := object["method"]; ok {
+		if _, ok := object["id"]; !ok {
+			object["id"] = jsonNull
+		}
+		return
+	}
+	for _, member := range []string{"result", "error"} {
+		if _, ok := object[member]; !ok {
+			object[member] = jsonNull
+		}
+	}
+}
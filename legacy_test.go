@@ -0,0 +1,138 @@
+package jsonrpc2
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToLegacyJson(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		Message string
+		legacy  string
+	}{
+		{`{"jsonrpc": "2.0", "method": "echo", "params": ["hello"], "id": 1}`, `{"method": "echo", "params": ["hello"], "id": 1}`},
+		{`{"jsonrpc": "2.0", "method": "echo", "params": ["hello"]}`, `{"method": "echo", "params": ["hello"], "id": null}`},
+		{`{"jsonrpc": "2.0", "result": "hello", "id": 1}`, `{"result": "hello", "error": null, "id": 1}`},
+		{`{"jsonrpc": "2.0", "error": {"code": -32601, "message": "Method not found"}, "id": 1}`, `{"result": null, "error": {"code": -32601, "message": "Method not found"}, "id": 1}`},
+		{`[{"jsonrpc": "2.0", "result": "hello", "id": 1}]`, `[{"result": "hello", "error": null, "id": 1}]`},
+	}
+	for _, test := range tests {
+		legacy, err := toLegacyJson([]byte(test.Message))
+		assert.Nil(err)
+		assert.JSONEq(test.legacy, string(legacy))
+	}
+}
+
+func TestUpgradeLegacy(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		Message string
+		kind    MessageKind
+	}{
+		{`{"method": "echo", "params": ["hello"], "id": 1}`, REQUEST_KIND},
+		{`{"method": "echo", "params": ["hello"], "id": null}`, NOTIFICATION_KIND},
+		{`{"result": "hello", "error": null, "id": 1}`, SUCCESS_RESPONSE_KIND},
+		{`{"result": null, "error": null, "id": 1}`, SUCCESS_RESPONSE_KIND},
+		{`{"result": null, "error": {"code": 1, "message": "failed"}, "id": 1}`, ERROR_RESPONSE_KIND},
+		{`{"jsonrpc": "2.0", "method": "echo", "params": ["hello"], "id": 1}`, REQUEST_KIND},
+	}
+	for _, test := range tests {
+		var rpcObj Object
+		assert.Nil(json.Unmarshal([]byte(test.Message), &rpcObj))
+		rpcObj.upgradeLegacy()
+		kind, err := rpcObj.GetSingleMessage().GetKind()
+		assert.Nil(err, test.Message)
+		assert.Equal(test.kind, kind, test.Message)
+	}
+}
+
+func TestStreamLegacyProtocol(t *testing.T) {
+	assert := assert.New(t)
+	connA, connB := net.Pipe()
+	notified := make(chan string, 1)
+	s := NewStreamEndpoint(context.Background(), NewPlainObjectStream(connA), func(s *StreamEndpoint) {
+		s.UseProtocolVersion(JsonRpc10)
+		RegisterEndpointMethod(s, "hello", func(ctx context.Context, name string) (string, *Error) {
+			return "Hello " + name, nil
+		})
+		RegisterEndpointMethod(s, "notify", func(ctx context.Context, name string) (interface{}, *Error) {
+			notified <- name
+			return nil, nil
+		})
+	})
+	defer s.Close()
+
+	decoder := json.NewDecoder(connB)
+	go connB.Write([]byte(`{"method": "notify", "params": ["World"], "id": null}`))
+	assert.Equal("World", <-notified)
+
+	go connB.Write([]byte(`{"method": "hello", "params": ["World"], "id": 1}`))
+	var response json.RawMessage
+	assert.Nil(decoder.Decode(&response))
+	assert.JSONEq(`{"result": "Hello World", "error": null, "id": 1}`, string(response))
+
+	go connB.Write([]byte(`{"method": "missing", "params": [], "id": 2}`))
+	assert.Nil(decoder.Decode(&response))
+	var legacyError map[string]json.RawMessage
+	assert.Nil(json.Unmarshal(response, &legacyError))
+	assert.Equal("null", string(legacyError["result"]))
+	assert.Equal("2", string(legacyError["id"]))
+	assert.NotContains(legacyError, "jsonrpc")
+}
+
+func TestStreamLegacyEndpoints(t *testing.T) {
+	assert := assert.New(t)
+	connA, connB := net.Pipe()
+	legacy := func(c *StreamEndpoint) {
+		c.UseProtocolVersion(JsonRpc10)
+	}
+	NewStreamEndpoint(context.Background(), NewPlainObjectStream(connA), legacy, func(s *StreamEndpoint) {
+		RegisterEndpointMethod(s, "hello", func(ctx context.Context, name string) (string, *Error) {
+			return "Hello " + name, nil
+		})
+	})
+	c := NewStreamEndpoint(context.Background(), NewPlainObjectStream(connB), legacy)
+
+	response, err := Request[[]string, string](context.Background(), c, "hello", []string{"World"})
+	assert.Nil(err)
+	assert.Equal("Hello World", response.Result)
+
+	response, err = Request[[]string, string](context.Background(), c, "missing", []string{"World"})
+	assert.Nil(err)
+	if assert.NotNil(response.Error) {
+		assert.Equal(-32601, response.Error.Code)
+	}
+}
+
+func TestHttpLegacyProtocol(t *testing.T) {
+	assert := assert.New(t)
+	mux := NewServerMux()
+	mux.UseProtocolVersion(JsonRpc10)
+	RegisterServerMuxEndpointMethod(mux, "/", "hello", func(ctx context.Context, name string) (string, *Error) {
+		return "Hello " + name, nil
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	httpResponse, err := http.Post(srv.URL, "application/json", bytes.NewReader([]byte(`{"method": "hello", "params": ["World"], "id": 1}`)))
+	assert.Nil(err)
+	body, err := io.ReadAll(httpResponse.Body)
+	httpResponse.Body.Close()
+	assert.Nil(err)
+	assert.JSONEq(`{"result": "Hello World", "error": null, "id": 1}`, string(body))
+
+	c := NewHttpClientEndpoint(srv.URL, nil)
+	c.UseProtocolVersion(JsonRpc10)
+	response, err := Request[[]string, string](context.Background(), c, "hello", []string{"World"})
+	assert.Nil(err)
+	assert.Equal("Hello World", response.Result)
+}
@@ -0,0 +1,137 @@
+package jsonrpc2
+
+import (
+	"reflect"
+	"runtime"
+	"sync"
+	"unsafe"
+	"weak"
+)
+
+// MethodInfo describes registered method. It is collected from method options
+// and types used on registration and used to generate OpenRPC document.
+type MethodInfo struct {
+	Name        string
+	Summary     string
+	Description string
+	Deprecated  bool
+	// Errors method is documented to return
+	Errors []*Error
+
+	ParamType  reflect.Type
+	ResultType reflect.Type
+	// ParamsSchema overrides schema generated from ParamType
+	ParamsSchema *Schema
+	// PositionalParams are names of params in order of positions if params can be sent as an array
+	PositionalParams []string
+}
+
+// WithSummary sets short summary of the method.
+func WithSummary(summary string) MethodOption {
+	return func(o *methodOptions) {
+		o.info.Summary = summary
+	}
+}
+
+// WithDescription sets verbose description of the method.
+func WithDescription(description string) MethodOption {
+	return func(o *methodOptions) {
+		o.info.Description = description
+	}
+}
+
+// WithDeprecated marks the method as deprecated.
+func WithDeprecated() MethodOption {
+	return func(o *methodOptions) {
+		o.info.Deprecated = true
+	}
+}
+
+// WithErrors documents errors the method can return.
+func WithErrors(errs ...*Error) MethodOption {
+	return func(o *methodOptions) {
+		o.info.Errors = append(o.info.Errors, errs...)
+	}
+}
+
+// methodTable is side table of a registry holding info of methods registered through
+// RegisterMethod and RegisterService
+type methodTable struct {
+	registry weak.Pointer[byte]
+	mutex    sync.RWMutex
+	methods  map[string]*methodEntry
+}
+
+type methodEntry struct {
+	info *MethodInfo
+	// code of request timeout error of the method
+	timeoutCode int
+}
+
+var (
+	// methodTables maps address of registry to its method table. Table is removed once the registry
+	// is collected and it is checked against weak pointer to the registry, so registry allocated
+	// at the address of collected one never gets its table.
+	methodTables      sync.Map
+	methodTablesMutex sync.Mutex
+)
+
+func getMethodTable(reg RpcMethodRegistry, create bool) *methodTable {
+	if reg == nil {
+		return nil
+	}
+	ptr := (*byte)(reflect.ValueOf(reg).UnsafePointer())
+	key := uintptr(unsafe.Pointer(ptr))
+	if table, ok := methodTables.Load(key); ok && table.(*methodTable).registry.Value() == ptr {
+		return table.(*methodTable)
+	}
+	if !create {
+		return nil
+	}
+
+	methodTablesMutex.Lock()
+	defer methodTablesMutex.Unlock()
+	if table, ok := methodTables.Load(key); ok && table.(*methodTable).registry.Value() == ptr {
+		return table.(*methodTable)
+	}
+	table := &methodTable{registry: weak.Make(ptr), methods: make(map[string]*methodEntry)}
+	methodTables.Store(key, table)
+	runtime.AddCleanup(ptr, func(table *methodTable) {
+		methodTables.CompareAndDelete(key, table)
+	}, table)
+	return table
+}
+
+func (t *methodTable) set(method string, entry *methodEntry) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.methods[method] = entry
+}
+
+func (t *methodTable) get(method string) *methodEntry {
+	if t == nil {
+		return nil
+	}
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	return t.methods[method]
+}
+
+// getMethodEntry returns entry of the method registered through RegisterMethod or RegisterService,
+// nil for methods added to the registry directly
+func getMethodEntry(reg RpcMethodRegistry, method string) *methodEntry {
+	return getMethodTable(reg, false).get(method)
+}
+
+// GetMethodInfo returns info of the method registered in the registry. Methods added
+// to the registry directly, not through RegisterMethod or RegisterService, have only name set.
+// Info is kept by the registry under method name, so it stays with the method wrapped by UseMiddleware.
+func GetMethodInfo(reg RpcMethodRegistry, method string) *MethodInfo {
+	if _, ok := reg[method]; !ok {
+		return nil
+	}
+	if entry := getMethodEntry(reg, method); entry != nil {
+		return entry.info
+	}
+	return &MethodInfo{Name: method}
+}
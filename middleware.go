@@ -0,0 +1,32 @@
+package jsonrpc2
+
+// RpcMiddleware wraps RpcHandler to add cross-cutting behavior like logging, auth or metrics.
+// Middleware can short-circuit the call by returning response without calling next,
+// e.g. NewInvalidRequest().ToResponse(rpcMsg.Id).
+type RpcMiddleware func(next RpcHandler) RpcHandler
+
+// ChainMiddleware wraps handler in middlewares. First middleware is the outermost one,
+// so it is the first to see the request and the last to see the response.
+func ChainMiddleware(handler RpcHandler, middlewares ...RpcMiddleware) RpcHandler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+// UseMiddleware wraps all methods currently registered in the registry in middlewares.
+// Methods registered later are not affected.
+func UseMiddleware(reg RpcMethodRegistry, middlewares ...RpcMiddleware) {
+	for method, handler := range reg {
+		reg[method] = ChainMiddleware(handler, middlewares...)
+	}
+}
+
+// GetResponseError returns error of the response returned by RpcHandler
+// or nil if it is not an error response.
+func GetResponseError(response interface{}) *ErrorObj {
+	if errResponse, ok := response.(*errorResponse); ok {
+		return errResponse.Error
+	}
+	return nil
+}
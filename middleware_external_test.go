@@ -0,0 +1,91 @@
+package jsonrpc2_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/alis-is/jsonrpc2"
+	"github.com/stretchr/testify/assert"
+)
+
+type greeting struct {
+	Name string `json:"name"`
+}
+
+func loggingMiddleware(log *[]string) jsonrpc2.RpcMiddleware {
+	return func(next jsonrpc2.RpcHandler) jsonrpc2.RpcHandler {
+		return func(ctx context.Context, rpcMsg *jsonrpc2.Message) interface{} {
+			request, err := jsonrpc2.DecodeRequest[greeting](rpcMsg, false)
+			if err != nil {
+				*log = append(*log, rpcMsg.Method+" invalid params")
+				return next(ctx, rpcMsg)
+			}
+			*log = append(*log, fmt.Sprintf("%s %v %s", rpcMsg.Method, rpcMsg.Id.Value(), request.Params.Name))
+			return next(ctx, rpcMsg)
+		}
+	}
+}
+
+func authMiddleware(token string) jsonrpc2.RpcMiddleware {
+	return func(next jsonrpc2.RpcHandler) jsonrpc2.RpcHandler {
+		return func(ctx context.Context, rpcMsg *jsonrpc2.Message) interface{} {
+			var meta struct {
+				Token string `json:"token"`
+			}
+			if len(rpcMsg.Meta) == 0 || json.Unmarshal(rpcMsg.Meta, &meta) != nil || meta.Token != token {
+				return jsonrpc2.NewServerErrorWithData(-32000, "unauthorized").ToResponse(rpcMsg.Id)
+			}
+			return next(ctx, rpcMsg)
+		}
+	}
+}
+
+func withToken(token string) jsonrpc2.ClientInterceptor {
+	return func(ctx context.Context, calls []*jsonrpc2.ClientCall, next jsonrpc2.ClientInvoker) ([]*jsonrpc2.Response[json.RawMessage], error) {
+		for _, call := range calls {
+			call.Meta = map[string]interface{}{"token": token}
+		}
+		return next(ctx, calls)
+	}
+}
+
+func TestExternalMiddleware(t *testing.T) {
+	assert := assert.New(t)
+	connA, connB := net.Pipe()
+	log := []string{}
+	called := 0
+	s := jsonrpc2.NewStreamEndpoint(context.Background(), jsonrpc2.NewPlainObjectStream(connA), func(s *jsonrpc2.StreamEndpoint) {
+		s.UseMiddleware(loggingMiddleware(&log), authMiddleware("secret"))
+		jsonrpc2.RegisterEndpointMethod(s, "hello", func(ctx context.Context, p greeting) (string, *jsonrpc2.Error) {
+			called++
+			return "Hello " + p.Name, nil
+		})
+	})
+	defer s.Close()
+	anonymous := jsonrpc2.NewStreamEndpoint(context.Background(), jsonrpc2.NewPlainObjectStream(connB))
+	defer anonymous.Close()
+
+	response, err := jsonrpc2.RequestWithId[int, greeting, string](context.Background(), anonymous, 1, "hello", greeting{Name: "World"})
+	assert.Nil(err)
+	_, err = response.Unwrap()
+	if assert.NotNil(err) {
+		assert.Contains(err.Error(), "unauthorized")
+	}
+	assert.Equal(0, called)
+
+	anonymous.UseInterceptors(withToken("secret"))
+	response, err = jsonrpc2.RequestWithId[int, greeting, string](context.Background(), anonymous, 2, "hello", greeting{Name: "World"})
+	assert.Nil(err)
+	result, err := response.Unwrap()
+	assert.Nil(err)
+	assert.Equal("Hello World", result)
+	assert.Equal(1, called)
+
+	_, err = jsonrpc2.RequestWithId[int, map[string]int, string](context.Background(), anonymous, 3, "hello", map[string]int{"name": 1})
+	assert.Nil(err)
+	assert.Equal(1, called)
+	assert.Equal([]string{"hello 1 World", "hello 2 World", "hello invalid params"}, log)
+}
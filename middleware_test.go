@@ -0,0 +1,113 @@
+package jsonrpc2
+
+import (
+	"context"
+	"net"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func tracingMiddleware(trace *[]string, name string) RpcMiddleware {
+	return func(next RpcHandler) RpcHandler {
+		return func(ctx context.Context, rpcMsg *Message) interface{} {
+			*trace = append(*trace, name+">"+rpcMsg.Method)
+			result := next(ctx, rpcMsg)
+			*trace = append(*trace, name+"<")
+			return result
+		}
+	}
+}
+
+func TestChainMiddlewareOrder(t *testing.T) {
+	assert := assert.New(t)
+	reg := NewMethodRegistry()
+	trace := []string{}
+	RegisterMethod(reg, "test", func(ctx context.Context, p string) (string, *Error) {
+		trace = append(trace, "handler")
+		return p, nil
+	}, WithMiddleware(tracingMiddleware(&trace, "m1"), tracingMiddleware(&trace, "m2")))
+	UseMiddleware(reg, tracingMiddleware(&trace, "r"))
+
+	processRpcRequest(context.Background(), reg, &Message{Method: "test", Id: &ID{"1"}}, []RpcMiddleware{tracingMiddleware(&trace, "e")}, nil, nil)
+	assert.Equal([]string{"e>test", "r>test", "m1>test", "m2>test", "handler", "m2<", "m1<", "r<", "e<"}, trace)
+}
+
+func TestMiddlewareShortCircuit(t *testing.T) {
+	assert := assert.New(t)
+	reg := NewMethodRegistry()
+	called := false
+	RegisterMethod(reg, "test", func(ctx context.Context, p string) (string, *Error) {
+		called = true
+		return p, nil
+	})
+	deny := func(next RpcHandler) RpcHandler {
+		return func(ctx context.Context, rpcMsg *Message) interface{} {
+			return NewInvalidRequestWithData("denied").ToResponse(rpcMsg.Id)
+		}
+	}
+
+	response := processRpcRequest(context.Background(), reg, &Message{Method: "test", Id: &ID{"1"}}, []RpcMiddleware{deny}, nil, nil)
+	assert.False(called)
+	errObj := GetResponseError(response)
+	if assert.NotNil(errObj) {
+		assert.Equal(-32600, errObj.Code)
+		assert.Equal("\"denied\"", string(*errObj.Data))
+	}
+	assert.Nil(GetResponseError(ProcessRpcRequest(context.Background(), reg, &Message{Method: "test", Id: &ID{"1"}})))
+}
+
+func TestStreamUseMiddleware(t *testing.T) {
+	assert := assert.New(t)
+	connA, connB := net.Pipe()
+	s := NewStreamEndpoint(context.Background(), NewPlainObjectStream(connA))
+	c := NewStreamEndpoint(context.Background(), NewPlainObjectStream(connB))
+	s.UseMiddleware(func(next RpcHandler) RpcHandler {
+		return func(ctx context.Context, rpcMsg *Message) interface{} {
+			if rpcMsg.Method == "secret" {
+				return NewMethodNotFound().ToResponse(rpcMsg.Id)
+			}
+			return next(ctx, rpcMsg)
+		}
+	})
+	RegisterEndpointMethod(s, "secret", func(ctx context.Context, data string) (string, *Error) {
+		return "secret " + data, nil
+	})
+	RegisterEndpointMethod(s, "public", func(ctx context.Context, data string) (string, *Error) {
+		return "public " + data, nil
+	})
+
+	response, err := Request[[]string, string](context.Background(), c, "secret", []string{"data"})
+	assert.Nil(err)
+	_, err = response.Unwrap()
+	assert.Contains(err.Error(), "Method not found")
+
+	response, err = Request[[]string, string](context.Background(), c, "public", []string{"data"})
+	assert.Nil(err)
+	result, err := response.Unwrap()
+	assert.Nil(err)
+	assert.Equal("public data", result)
+}
+
+func TestServerMuxUseEndpointMiddleware(t *testing.T) {
+	assert := assert.New(t)
+	mux := NewServerMux()
+	trace := []string{}
+	mux.UseMiddleware(tracingMiddleware(&trace, "mux"))
+	mux.UseEndpointMiddleware("/hello", tracingMiddleware(&trace, "hello"))
+	RegisterServerMuxEndpointMethod(mux, "/hello", "hello", func(ctx context.Context, name string) (string, *Error) {
+		return "Hello " + name, nil
+	})
+	RegisterServerMuxEndpointMethod(mux, "/bye", "bye", func(ctx context.Context, name string) (string, *Error) {
+		return "Bye " + name, nil
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	_, err := Request[[]string, string](context.Background(), NewHttpClientEndpoint(srv.URL+"/hello", nil), "hello", []string{"World"})
+	assert.Nil(err)
+	_, err = Request[[]string, string](context.Background(), NewHttpClientEndpoint(srv.URL+"/bye", nil), "bye", []string{"World"})
+	assert.Nil(err)
+	assert.Equal([]string{"mux>hello", "hello>hello", "hello<", "mux<", "mux>bye", "mux<"}, trace)
+}
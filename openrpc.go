@@ -0,0 +1,153 @@
+package jsonrpc2
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sort"
+)
+
+const (
+	OpenRpcVersion = "1.2.6"
+	DiscoverMethod = "rpc.discover"
+)
+
+type OpenRpcInfo struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+type OpenRpcContentDescriptor struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type OpenRpcError struct {
+	Code    int              `json:"code"`
+	Message string           `json:"message"`
+	Data    *json.RawMessage `json:"data,omitempty"`
+}
+
+type OpenRpcMethod struct {
+	Name           string                     `json:"name"`
+	Summary        string                     `json:"summary,omitempty"`
+	Description    string                     `json:"description,omitempty"`
+	Params         []OpenRpcContentDescriptor `json:"params"`
+	Result         *OpenRpcContentDescriptor  `json:"result,omitempty"`
+	Errors         []OpenRpcError             `json:"errors,omitempty"`
+	Deprecated     bool                       `json:"deprecated,omitempty"`
+	ParamStructure string                     `json:"paramStructure,omitempty"`
+}
+
+// OpenRpcDocument is OpenRPC 1.x service description
+type OpenRpcDocument struct {
+	OpenRpc string          `json:"openrpc"`
+	Info    OpenRpcInfo     `json:"info"`
+	Methods []OpenRpcMethod `json:"methods"`
+}
+
+// NewOpenRpcDocument generates OpenRPC document describing methods of the registry.
+func NewOpenRpcDocument(info OpenRpcInfo, reg RpcMethodRegistry) *OpenRpcDocument {
+	methods := make([]string, 0, len(reg))
+	for method := range reg {
+		if method == DiscoverMethod {
+			continue
+		}
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+
+	document := &OpenRpcDocument{
+		OpenRpc: OpenRpcVersion,
+		Info:    info,
+		Methods: make([]OpenRpcMethod, 0, len(methods)),
+	}
+	for _, method := range methods {
+		document.Methods = append(document.Methods, newOpenRpcMethod(GetMethodInfo(reg, method)))
+	}
+	return document
+}
+
+func newOpenRpcMethod(info *MethodInfo) OpenRpcMethod {
+	paramsSchema := info.ParamsSchema
+	if paramsSchema == nil && info.ParamType != nil {
+		paramsSchema = SchemaFor(info.ParamType)
+	}
+	params, byName := newOpenRpcParams(paramsSchema, info.PositionalParams)
+	method := OpenRpcMethod{
+		Name:        info.Name,
+		Summary:     info.Summary,
+		Description: info.Description,
+		Deprecated:  info.Deprecated,
+		Params:      params,
+		Result:      &OpenRpcContentDescriptor{Name: "result", Schema: SchemaFor(info.ResultType)},
+	}
+	if byName {
+		method.ParamStructure = "by-name"
+		if len(info.PositionalParams) > 0 {
+			method.ParamStructure = "either"
+		}
+	}
+	for _, err := range info.Errors {
+		errObj := err.toErrorObj()
+		method.Errors = append(method.Errors, OpenRpcError{Code: errObj.Code, Message: errObj.Message, Data: errObj.Data})
+	}
+	return method
+}
+
+// newOpenRpcParams describes every property of object params as a separate param,
+// other params are described as a single param
+func newOpenRpcParams(schema *Schema, positions []string) ([]OpenRpcContentDescriptor, bool) {
+	if schema == nil {
+		return []OpenRpcContentDescriptor{}, false
+	}
+	if len(schema.Type) != 1 || !schema.Type.has("object") || len(schema.Properties) == 0 {
+		return []OpenRpcContentDescriptor{{Name: "params", Schema: schema}}, false
+	}
+
+	names := schema.propertyOrder
+	if len(names) != len(schema.Properties) {
+		names = make([]string, 0, len(schema.Properties))
+		for name := range schema.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+	if len(positions) > 0 {
+		names = append([]string{}, names...)
+		sortByPositions(names, positions)
+	}
+	required := make(map[string]bool, len(schema.Required))
+	for _, name := range schema.Required {
+		required[name] = true
+	}
+	params := make([]OpenRpcContentDescriptor, 0, len(names))
+	for _, name := range names {
+		property := schema.Properties[name]
+		params = append(params, OpenRpcContentDescriptor{
+			Name:        name,
+			Description: property.Description,
+			Required:    required[name],
+			Schema:      property,
+		})
+	}
+	return params, true
+}
+
+// RegisterDiscoverMethod registers rpc.discover method returning OpenRPC document
+// describing all methods of the registry, including methods registered later.
+func RegisterDiscoverMethod(reg RpcMethodRegistry, info OpenRpcInfo) {
+	// rpc.discover takes no params, so it is registered without param type
+	options := newMethodOptions([]MethodOption{WithSummary("Returns an OpenRPC schema as a description of this service")})
+	registerHandler(reg, DiscoverMethod, nil, reflect.TypeFor[*OpenRpcDocument](), func(ctx context.Context, rpcMsg *Message) interface{} {
+		return NewSuccessResponseI(rpcMsg.Id, NewOpenRpcDocument(info, reg))
+	}, options)
+}
+
+// UseDiscovery registers rpc.discover method describing methods of the endpoint.
+func (c *StreamEndpoint) UseDiscovery(info OpenRpcInfo) {
+	RegisterDiscoverMethod(c.methodRegistry, info)
+}
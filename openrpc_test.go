@@ -0,0 +1,189 @@
+package jsonrpc2
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http/httptest"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+	"weak"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testBlockParams struct {
+	Height  int64   `json:"height" description:"height of the block"`
+	Verbose bool    `json:"verbose,omitempty"`
+	Hash    *string `json:"hash"`
+	secret  string
+}
+
+type testBlock struct {
+	Hash         string            `json:"hash"`
+	Transactions []string          `json:"transactions"`
+	Labels       map[string]string `json:"labels,omitempty"`
+	Parent       *testBlock        `json:"parent,omitempty"`
+}
+
+func TestSchemaFor(t *testing.T) {
+	assert := assert.New(t)
+	schema, err := json.Marshal(SchemaFor(reflect.TypeFor[testBlock]()))
+	assert.Nil(err)
+	assert.JSONEq(`{
+		"type": "object",
+		"properties": {
+			"hash": {"type": "string"},
+			"transactions": {"type": ["array", "null"], "items": {"type": "string"}},
+			"labels": {"type": ["object", "null"], "additionalProperties": {"type": "string"}},
+			"parent": {}
+		},
+		"required": ["hash", "transactions"]
+	}`, string(schema))
+
+	schema, err = json.Marshal(SchemaFor(reflect.TypeFor[testBlockParams]()))
+	assert.Nil(err)
+	assert.JSONEq(`{
+		"type": "object",
+		"properties": {
+			"height": {"type": "integer", "description": "height of the block"},
+			"verbose": {"type": "boolean"},
+			"hash": {"type": ["string", "null"]}
+		},
+		"required": ["height"]
+	}`, string(schema))
+}
+
+func TestNewOpenRpcDocument(t *testing.T) {
+	assert := assert.New(t)
+	reg := NewMethodRegistry()
+	RegisterMethod(reg, "getBlock", func(ctx context.Context, p testBlockParams) (*testBlock, *Error) {
+		return nil, nil
+	}, WithSummary("Get block"), WithDescription("Returns block at height"), WithErrors(NewInvalidParams()))
+	RegisterMethod(reg, "echo", func(ctx context.Context, p string) (string, *Error) {
+		return p, nil
+	}, WithDeprecated())
+	reg["raw"] = func(ctx context.Context, rpcMsg *Message) interface{} { return nil }
+	RegisterDiscoverMethod(reg, OpenRpcInfo{Title: "test", Version: "1.0.0"})
+
+	document, err := json.Marshal(NewOpenRpcDocument(OpenRpcInfo{Title: "test", Version: "1.0.0"}, reg))
+	assert.Nil(err)
+	assert.JSONEq(`{
+		"openrpc": "1.2.6",
+		"info": {"title": "test", "version": "1.0.0"},
+		"methods": [
+			{
+				"name": "echo",
+				"deprecated": true,
+				"params": [{"name": "params", "schema": {"type": "string"}}],
+				"result": {"name": "result", "schema": {"type": "string"}}
+			},
+			{
+				"name": "getBlock",
+				"summary": "Get block",
+				"description": "Returns block at height",
+				"paramStructure": "by-name",
+				"params": [
+					{"name": "height", "description": "height of the block", "required": true, "schema": {"type": "integer", "description": "height of the block"}},
+					{"name": "verbose", "schema": {"type": "boolean"}},
+					{"name": "hash", "schema": {"type": ["string", "null"]}}
+				],
+				"result": {"name": "result", "schema": {
+					"type": ["object", "null"],
+					"properties": {
+						"hash": {"type": "string"},
+						"transactions": {"type": ["array", "null"], "items": {"type": "string"}},
+						"labels": {"type": ["object", "null"], "additionalProperties": {"type": "string"}},
+						"parent": {}
+					},
+					"required": ["hash", "transactions"]
+				}},
+				"errors": [{"code": -32602, "message": "Invalid params"}]
+			},
+			{
+				"name": "raw",
+				"params": [],
+				"result": {"name": "result", "schema": {}}
+			}
+		]
+	}`, string(document))
+
+	discover := newOpenRpcMethod(GetMethodInfo(reg, DiscoverMethod))
+	assert.NotNil(discover.Params)
+	assert.Empty(discover.Params)
+}
+
+func TestStreamDiscovery(t *testing.T) {
+	assert := assert.New(t)
+	connA, connB := net.Pipe()
+	s := NewStreamEndpoint(context.Background(), NewPlainObjectStream(connA))
+	c := NewStreamEndpoint(context.Background(), NewPlainObjectStream(connB))
+	s.UseDiscovery(OpenRpcInfo{Title: "stream", Version: "1.0.0"})
+	RegisterEndpointMethod(s, "hello", func(ctx context.Context, name string) (string, *Error) {
+		return "Hello " + name, nil
+	})
+
+	response, err := Request[interface{}, OpenRpcDocument](context.Background(), c, DiscoverMethod, nil)
+	assert.Nil(err)
+	document, err := response.Unwrap()
+	assert.Nil(err)
+	assert.Equal("stream", document.Info.Title)
+	if assert.Len(document.Methods, 1) {
+		assert.Equal("hello", document.Methods[0].Name)
+	}
+}
+
+func TestServerMuxDiscovery(t *testing.T) {
+	assert := assert.New(t)
+	mux := NewServerMux()
+	mux.UseDiscovery(OpenRpcInfo{Title: "mux", Version: "1.0.0"})
+	RegisterServerMuxEndpointMethod(mux, "/hello", "hello", func(ctx context.Context, name string) (string, *Error) {
+		return "Hello " + name, nil
+	})
+	RegisterServerMuxEndpointMethod(mux, "/bye", "bye", func(ctx context.Context, name string) (string, *Error) {
+		return "Bye " + name, nil
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	for _, method := range []string{"hello", "bye"} {
+		response, err := Request[interface{}, OpenRpcDocument](context.Background(), NewHttpClientEndpoint(srv.URL+"/"+method, nil), DiscoverMethod, nil)
+		assert.Nil(err)
+		document, err := response.Unwrap()
+		assert.Nil(err)
+		if assert.Len(document.Methods, 1) {
+			assert.Equal(method, document.Methods[0].Name)
+		}
+	}
+}
+
+func TestMethodInfo(t *testing.T) {
+	assert := assert.New(t)
+	reg := NewMethodRegistry()
+	RegisterMethod(reg, "echo", func(ctx context.Context, p string) (string, *Error) {
+		return p, nil
+	}, WithSummary("Echo"))
+	UseMiddleware(reg, func(next RpcHandler) RpcHandler { return next })
+	assert.Equal("Echo", GetMethodInfo(reg, "echo").Summary)
+	assert.Nil(GetMethodInfo(reg, "missing"))
+
+	// info belongs to the registry the method is registered in
+	other := NewMethodRegistry()
+	RegisterMethod(other, "echo", func(ctx context.Context, p string) (string, *Error) {
+		return p, nil
+	}, WithSummary("Other echo"))
+	assert.Equal("Echo", GetMethodInfo(reg, "echo").Summary)
+	assert.Equal("Other echo", GetMethodInfo(other, "echo").Summary)
+	other["raw"] = func(ctx context.Context, rpcMsg *Message) interface{} { return nil }
+	assert.Equal(&MethodInfo{Name: "raw"}, GetMethodInfo(other, "raw"))
+
+	// info does not keep registry alive
+	info := weak.Make(GetMethodInfo(reg, "echo"))
+	reg = nil
+	assert.Eventually(func() bool {
+		runtime.GC()
+		return info.Value() == nil
+	}, 5*time.Second, 10*time.Millisecond)
+}
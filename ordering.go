@@ -0,0 +1,85 @@
+package jsonrpc2
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// OrderingKey returns key of received message. Messages with the same key are processed one
+// after another in the order they were received, messages which are not ordered are processed
+// concurrently. Batch is ordered by the first ordered message in it.
+type OrderingKey func(method string, params json.RawMessage) (key string, ordered bool)
+
+// SequentialOrder processes all messages one after another in the order they were received.
+func SequentialOrder() OrderingKey {
+	return func(string, json.RawMessage) (string, bool) {
+		return "", true
+	}
+}
+
+// OrderMethods processes messages of the methods in the order they were received,
+// other messages are processed concurrently.
+func OrderMethods(methods ...string) OrderingKey {
+	ordered := make(map[string]bool, len(methods))
+	for _, method := range methods {
+		ordered[method] = true
+	}
+	return func(method string, _ json.RawMessage) (string, bool) {
+		return "", ordered[method]
+	}
+}
+
+// OrderByParam processes messages with the same value of params field in the order they
+// were received, e.g. OrderByParam("textDocument.uri"). Path is dot separated, messages
+// without the field are processed concurrently. If methods are given only their messages are ordered.
+func OrderByParam(path string, methods ...string) OrderingKey {
+	fields := strings.Split(path, ".")
+	var isOrderedMethod OrderingKey
+	if len(methods) > 0 {
+		isOrderedMethod = OrderMethods(methods...)
+	}
+	return func(method string, params json.RawMessage) (string, bool) {
+		if isOrderedMethod != nil {
+			if _, ok := isOrderedMethod(method, params); !ok {
+				return "", false
+			}
+		}
+		value := params
+		for _, field := range fields {
+			var object map[string]json.RawMessage
+			if err := json.Unmarshal(value, &object); err != nil {
+				return "", false
+			}
+			if value = object[field]; value == nil {
+				return "", false
+			}
+		}
+		var compacted bytes.Buffer
+		if err := json.Compact(&compacted, value); err != nil {
+			return "", false
+		}
+		return compacted.String(), true
+	}
+}
+
+// UseOrdering sets which received messages are processed in order. By default all messages
+// are processed concurrently. Should be set before the first message is received,
+// in ConnOpt passed to NewStreamEndpoint.
+func (c *StreamEndpoint) UseOrdering(ordering OrderingKey) {
+	c.scheduler.mutex.Lock()
+	defer c.scheduler.mutex.Unlock()
+	c.scheduler.ordering = ordering
+}
+
+func (s *messageScheduler) orderingKey(messages []Message) (string, bool) {
+	if s.ordering == nil {
+		return "", false
+	}
+	for _, rpcMsg := range messages {
+		if key, ok := s.ordering(rpcMsg.Method, rpcMsg.Params); ok {
+			return key, true
+		}
+	}
+	return "", false
+}
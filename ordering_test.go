@@ -0,0 +1,162 @@
+package jsonrpc2
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testDidChangeParams struct {
+	TextDocument struct {
+		Uri string `json:"uri"`
+	} `json:"textDocument"`
+	Version int `json:"version"`
+}
+
+func newDidChange(uri string, version int) testDidChangeParams {
+	var params testDidChangeParams
+	params.TextDocument.Uri = uri
+	params.Version = version
+	return params
+}
+
+func newOrderedStreamEndpoints(ordering OrderingKey, limits ConcurrencyLimits) (*StreamEndpoint, *StreamEndpoint) {
+	connA, connB := net.Pipe()
+	s := NewStreamEndpoint(context.Background(), NewPlainObjectStream(connA), func(s *StreamEndpoint) {
+		s.UseOrdering(ordering)
+		s.UseConcurrencyLimits(limits)
+	})
+	return s, NewStreamEndpoint(context.Background(), NewPlainObjectStream(connB))
+}
+
+func TestStreamSequentialOrder(t *testing.T) {
+	assert := assert.New(t)
+	for _, limits := range []ConcurrencyLimits{{}, {MaxHandlers: 1, MaxQueued: 100}} {
+		s, c := newOrderedStreamEndpoints(SequentialOrder(), limits)
+		var mutex sync.Mutex
+		received := make([]int, 0, 20)
+		done := make(chan struct{})
+		RegisterEndpointMethod(s, "didChange", func(ctx context.Context, p testDidChangeParams) (interface{}, *Error) {
+			time.Sleep(time.Duration(rand.Intn(3)) * time.Millisecond)
+			mutex.Lock()
+			defer mutex.Unlock()
+			received = append(received, p.Version)
+			if len(received) == 20 {
+				close(done)
+			}
+			return nil, nil
+		})
+
+		expected := make([]int, 0, 20)
+		for i := 0; i < 20; i++ {
+			expected = append(expected, i)
+			assert.Nil(Notify(context.Background(), c, "didChange", newDidChange("file:///a", i)))
+		}
+		<-done
+		assert.Equal(expected, received)
+	}
+}
+
+func TestStreamOrderByParam(t *testing.T) {
+	assert := assert.New(t)
+	s, c := newOrderedStreamEndpoints(OrderByParam("textDocument.uri", "didChange"), ConcurrencyLimits{})
+	release := make(chan struct{})
+	var mutex sync.Mutex
+	received := make(map[string][]int)
+	RegisterEndpointMethod(s, "didChange", func(ctx context.Context, p testDidChangeParams) (int, *Error) {
+		if p.TextDocument.Uri == "file:///a" && p.Version == 1 {
+			<-release
+		}
+		mutex.Lock()
+		defer mutex.Unlock()
+		received[p.TextDocument.Uri] = append(received[p.TextDocument.Uri], p.Version)
+		return p.Version, nil
+	})
+
+	calls := make([]*Call[int], 0, 3)
+	for i := 1; i <= 3; i++ {
+		calls = append(calls, Go[testDidChangeParams, int](context.Background(), c, "didChange", newDidChange("file:///a", i)))
+	}
+	for i := 1; i <= 3; i++ {
+		response, err := Request[testDidChangeParams, int](context.Background(), c, "didChange", newDidChange("file:///b", i))
+		assert.Nil(err)
+		assert.Equal(i, response.Result)
+	}
+	assert.Eventually(func() bool {
+		stats := s.ConcurrencyStats()
+		return stats.ActiveHandlers == 1 && stats.QueuedMessages == 2
+	}, time.Second, 10*time.Millisecond)
+
+	close(release)
+	for _, call := range calls {
+		_, err := call.Wait(context.Background())
+		assert.Nil(err)
+	}
+	assert.Equal(map[string][]int{"file:///a": {1, 2, 3}, "file:///b": {1, 2, 3}}, received)
+}
+
+func TestStreamOrderedQueueLimit(t *testing.T) {
+	assert := assert.New(t)
+	// no limit of handlers, queue of messages waiting for the same key is limited on its own
+	s, c := newOrderedStreamEndpoints(OrderByParam("textDocument.uri", "didChange"), ConcurrencyLimits{MaxQueued: 2, Policy: OverflowReject})
+	release := make(chan struct{})
+	RegisterEndpointMethod(s, "didChange", func(ctx context.Context, p testDidChangeParams) (int, *Error) {
+		if p.TextDocument.Uri == "file:///a" {
+			<-release
+		}
+		return p.Version, nil
+	})
+
+	calls := make([]*Call[int], 0, 3)
+	for i := 1; i <= 3; i++ {
+		calls = append(calls, Go[testDidChangeParams, int](context.Background(), c, "didChange", newDidChange("file:///a", i)))
+	}
+	assert.Eventually(func() bool {
+		stats := s.ConcurrencyStats()
+		return stats.ActiveHandlers == 1 && stats.QueuedMessages == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	response, err := Request[testDidChangeParams, int](context.Background(), c, "didChange", newDidChange("file:///a", 4))
+	assert.Nil(err)
+	if assert.NotNil(response.Error) {
+		assert.Equal(DefaultOverloadedErrorCode, response.Error.Code)
+	}
+	// messages with other keys run right away
+	response, err = Request[testDidChangeParams, int](context.Background(), c, "didChange", newDidChange("file:///b", 1))
+	assert.Nil(err)
+	assert.Equal(1, response.Result)
+
+	close(release)
+	for i, call := range calls {
+		response, err := call.Wait(context.Background())
+		assert.Nil(err)
+		assert.Equal(i+1, response.Result)
+	}
+	assert.Equal(uint64(1), s.ConcurrencyStats().Rejected)
+}
+
+func TestOrderingKeys(t *testing.T) {
+	assert := assert.New(t)
+	key, ordered := OrderMethods("a")("a", nil)
+	assert.True(ordered)
+	assert.Equal("", key)
+	_, ordered = OrderMethods("a")("b", nil)
+	assert.False(ordered)
+
+	byUri := OrderByParam("textDocument.uri")
+	key, ordered = byUri("didChange", json.RawMessage(`{"textDocument": {"uri": "file:///a"}}`))
+	assert.True(ordered)
+	assert.Equal(`"file:///a"`, key)
+	_, ordered = byUri("didChange", json.RawMessage(`{"textDocument": {}}`))
+	assert.False(ordered)
+	_, ordered = byUri("didChange", json.RawMessage(`["file:///a"]`))
+	assert.False(ordered)
+	_, ordered = OrderByParam("textDocument.uri", "didOpen")("didChange", json.RawMessage(`{"textDocument": {"uri": "file:///a"}}`))
+	assert.False(ordered)
+}
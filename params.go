@@ -0,0 +1,72 @@
+package jsonrpc2
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+)
+
+// ParamsDecoder can be implemented by pointer to param type to take over decoding of params.
+// Returned error is reported to the client as invalid params error.
+type ParamsDecoder interface {
+	DecodeParams(params json.RawMessage) error
+}
+
+// ParamsDecodingError is data of invalid params error returned when params can not be decoded
+type ParamsDecodingError struct {
+	Message string `json:"message"`
+	// Field is path of the field which failed to decode, e.g. block.height
+	Field string `json:"field,omitempty"`
+	// Offset is byte offset in params where decoding failed
+	Offset int64 `json:"offset,omitempty"`
+}
+
+// WithStrictParams rejects params with fields unknown to the param type.
+func WithStrictParams() MethodOption {
+	return func(o *methodOptions) {
+		o.strictParams = true
+	}
+}
+
+// decodeParams decodes params into target, missing params leave target untouched
+func decodeParams(params json.RawMessage, target interface{}, strict bool) *Error {
+	if decoder, ok := target.(ParamsDecoder); ok {
+		if err := decoder.DecodeParams(params); err != nil {
+			return NewInvalidParamsWithData(newParamsDecodingError(err))
+		}
+		return nil
+	}
+	if params == nil {
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(params))
+	if strict {
+		decoder.DisallowUnknownFields()
+	}
+	if err := decoder.Decode(target); err != nil {
+		return NewInvalidParamsWithData(newParamsDecodingError(err))
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return NewInvalidParamsWithData(ParamsDecodingError{Message: "unexpected data after params", Offset: decoder.InputOffset()})
+	}
+	return nil
+}
+
+func newParamsDecodingError(err error) ParamsDecodingError {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return ParamsDecodingError{Message: err.Error(), Field: typeErr.Field, Offset: typeErr.Offset}
+	}
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return ParamsDecodingError{Message: err.Error(), Offset: syntaxErr.Offset}
+	}
+	// encoding/json does not expose unknown field error type
+	if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		return ParamsDecodingError{Message: err.Error(), Field: strings.Trim(field, `"`)}
+	}
+	return ParamsDecodingError{Message: err.Error()}
+}
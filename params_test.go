@@ -0,0 +1,105 @@
+package jsonrpc2
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testBlockQuery struct {
+	Block struct {
+		Height int64 `json:"height"`
+	} `json:"block"`
+}
+
+type testUpperParams struct {
+	Value string
+}
+
+func (p *testUpperParams) DecodeParams(params json.RawMessage) error {
+	var value string
+	if err := json.Unmarshal(params, &value); err != nil {
+		return errors.New("expected string")
+	}
+	p.Value = strings.ToUpper(value)
+	return nil
+}
+
+func paramsDecodingError(t *testing.T, err *Error) ParamsDecodingError {
+	var data ParamsDecodingError
+	if assert.NotNil(t, err) {
+		assert.Equal(t, InvalidParamsKind, err.Kind)
+		assert.IsType(t, ParamsDecodingError{}, err.Data)
+		data, _ = err.Data.(ParamsDecodingError)
+	}
+	return data
+}
+
+func TestDecodeRequest(t *testing.T) {
+	assert := assert.New(t)
+	request, err := DecodeRequest[testBlockQuery](&Message{Params: json.RawMessage(`{"block": {"height": 10}}`)}, true)
+	assert.Nil(err)
+	assert.Equal(int64(10), request.Params.Block.Height)
+
+	_, err = DecodeRequest[testBlockQuery](&Message{Params: json.RawMessage(`{"block": {"height": "10"}}`)}, false)
+	data := paramsDecodingError(t, err)
+	assert.Equal("block.height", data.Field)
+	assert.NotZero(data.Offset)
+
+	_, err = DecodeRequest[testBlockQuery](&Message{Params: json.RawMessage(`{"block": {"height": 10, "hash": "0x"}}`)}, false)
+	assert.Nil(err)
+	_, err = DecodeRequest[testBlockQuery](&Message{Params: json.RawMessage(`{"block": {"height": 10, "hash": "0x"}}`)}, true)
+	assert.Equal("hash", paramsDecodingError(t, err).Field)
+
+	request, err = DecodeRequest[testBlockQuery](&Message{}, true)
+	assert.Nil(err)
+	assert.Zero(request.Params.Block.Height)
+
+	assert.Nil(MessageToRequest[testBlockQuery](&Message{Params: json.RawMessage(`[]`)}))
+}
+
+func TestDecodeRequestCustomDecoder(t *testing.T) {
+	assert := assert.New(t)
+	request, err := DecodeRequest[testUpperParams](&Message{Params: json.RawMessage(`"hello"`)}, false)
+	assert.Nil(err)
+	assert.Equal("HELLO", request.Params.Value)
+
+	_, err = DecodeRequest[testUpperParams](&Message{Params: json.RawMessage(`1`)}, false)
+	assert.Equal("expected string", paramsDecodingError(t, err).Message)
+}
+
+func TestStreamInvalidParams(t *testing.T) {
+	assert := assert.New(t)
+	connA, connB := net.Pipe()
+	s := NewStreamEndpoint(context.Background(), NewPlainObjectStream(connA))
+	c := NewStreamEndpoint(context.Background(), NewPlainObjectStream(connB))
+	RegisterEndpointMethod(s, "height", func(ctx context.Context, p testBlockQuery) (int64, *Error) {
+		return p.Block.Height, nil
+	}, WithStrictParams())
+
+	response, err := Request[map[string]interface{}, int64](context.Background(), c, "height", map[string]interface{}{"block": map[string]interface{}{"height": "high"}})
+	assert.Nil(err)
+	if assert.NotNil(response.Error) {
+		assert.Equal(-32602, response.Error.Code)
+		var data ParamsDecodingError
+		assert.Nil(json.Unmarshal(*response.Error.Data, &data))
+		assert.Equal("block.height", data.Field)
+	}
+
+	response, err = Request[map[string]interface{}, int64](context.Background(), c, "height", map[string]interface{}{"blocks": nil})
+	assert.Nil(err)
+	if assert.NotNil(response.Error) {
+		assert.Equal(-32602, response.Error.Code)
+	}
+
+	response, err = Request[map[string]interface{}, int64](context.Background(), c, "height", map[string]interface{}{"block": map[string]interface{}{"height": 5}})
+	assert.Nil(err)
+	result, err := response.Unwrap()
+	assert.Nil(err)
+	assert.Equal(int64(5), result)
+}
@@ -0,0 +1,216 @@
+package jsonrpc2
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// positionalParam maps position in params array to field of params object
+type positionalParam struct {
+	name     string
+	optional bool
+}
+
+// WithPositionalParams allows params of the method to be sent as an array. Names are json
+// names of param fields in order of positions, name followed by ",optional" marks param
+// which can be omitted at the end of the array. Overrides jsonrpc struct tags.
+func WithPositionalParams(names ...string) MethodOption {
+	return func(o *methodOptions) {
+		o.positionalParams = make([]positionalParam, 0, len(names))
+		for _, name := range names {
+			name, option, _ := strings.Cut(name, ",")
+			o.positionalParams = append(o.positionalParams, positionalParam{name: name, optional: option == "optional"})
+		}
+	}
+}
+
+// getPositionalParams returns positions of struct fields from jsonrpc tags, e.g. `jsonrpc:"0"` or `jsonrpc:"1,optional"`
+func getPositionalParams(t reflect.Type) ([]positionalParam, error) {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, nil
+	}
+
+	positions := make(map[int]positionalParam)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag, ok := field.Tag.Lookup("jsonrpc")
+		if !ok {
+			continue
+		}
+		name, _, encoded := jsonFieldName(field)
+		if !encoded {
+			return nil, fmt.Errorf("jsonrpc2: field %s with jsonrpc tag is not encoded", field.Name)
+		}
+		if name == "" {
+			name = field.Name
+		}
+		position, option, _ := strings.Cut(tag, ",")
+		index, err := strconv.Atoi(position)
+		if err != nil || index < 0 {
+			return nil, fmt.Errorf("jsonrpc2: invalid position of field %s: %s", field.Name, position)
+		}
+		if _, ok := positions[index]; ok {
+			return nil, fmt.Errorf("jsonrpc2: duplicate position %d of field %s", index, field.Name)
+		}
+		positions[index] = positionalParam{name: name, optional: option == "optional"}
+	}
+	if len(positions) == 0 {
+		return nil, nil
+	}
+
+	result := make([]positionalParam, len(positions))
+	for index, param := range positions {
+		if index >= len(positions) {
+			return nil, fmt.Errorf("jsonrpc2: positions of %s are not continuous", t.Name())
+		}
+		result[index] = param
+	}
+	return result, nil
+}
+
+// withPositionalParams converts params sent as an array to object before they are validated and decoded
+func withPositionalParams(positional []positionalParam, handler RpcHandler) RpcHandler {
+	return func(ctx context.Context, rpcMsg *Message) interface{} {
+		if !isJsonArray(rpcMsg.Params) {
+			return handler(ctx, rpcMsg)
+		}
+		params, err := positionalToNamed(rpcMsg.Params, positional)
+		if err != nil {
+			return err.ToResponse(rpcMsg.Id)
+		}
+		namedMsg := *rpcMsg
+		namedMsg.Params = params
+		return handler(ctx, &namedMsg)
+	}
+}
+
+func isJsonArray(data json.RawMessage) bool {
+	data = bytes.TrimSpace(data)
+	return len(data) > 0 && data[0] == '['
+}
+
+// positionalToNamed converts params array to object with fields named by positions
+func positionalToNamed(params json.RawMessage, positional []positionalParam) (json.RawMessage, *Error) {
+	var values []json.RawMessage
+	if err := json.Unmarshal(params, &values); err != nil {
+		return nil, NewInvalidParamsWithData(newParamsDecodingError(err))
+	}
+	if len(values) > len(positional) {
+		return nil, NewInvalidParamsWithData(ParamsDecodingError{Message: fmt.Sprintf("too many params, expected at most %d", len(positional))})
+	}
+	named := make(map[string]json.RawMessage, len(values))
+	for i, param := range positional {
+		if i >= len(values) {
+			if !param.optional {
+				return nil, NewInvalidParamsWithData(ParamsDecodingError{Message: fmt.Sprintf("missing param at position %d", i), Field: param.name})
+			}
+			continue
+		}
+		named[param.name] = values[i]
+	}
+	result, err := json.Marshal(named)
+	if err != nil {
+		return nil, NewInvalidParamsWithData(newParamsDecodingError(err))
+	}
+	return result, nil
+}
+
+// PositionalParams marshals struct params as an array ordered by jsonrpc tags of its fields.
+// Omitted trailing optional params are not sent.
+type PositionalParams[T any] struct {
+	Params T
+}
+
+// AsPositional wraps params so they are sent as an array, e.g. NewRequest(id, method, AsPositional(params)).
+func AsPositional[T any](params T) PositionalParams[T] {
+	return PositionalParams[T]{Params: params}
+}
+
+func (p PositionalParams[T]) MarshalJSON() ([]byte, error) {
+	positional, err := getPositionalParams(reflect.TypeFor[T]())
+	if err != nil {
+		return nil, err
+	}
+	if positional == nil {
+		return nil, fmt.Errorf("jsonrpc2: %s has no fields with jsonrpc tag", reflect.TypeFor[T]())
+	}
+	data, err := json.Marshal(p.Params)
+	if err != nil {
+		return nil, err
+	}
+	var named map[string]json.RawMessage
+	if err := json.Unmarshal(data, &named); err != nil {
+		return nil, err
+	}
+
+	values := make([]json.RawMessage, len(positional))
+	length := 0
+	for i, param := range positional {
+		value, ok := named[param.name]
+		if !ok {
+			value = json.RawMessage("null")
+		}
+		if ok || !param.optional {
+			length = i + 1
+		}
+		values[i] = value
+	}
+	return json.Marshal(values[:length])
+}
+
+// optionalParamsSchema generates schema of params in which optional positional params
+// are not required, nil if there are no optional params
+func optionalParamsSchema(paramType reflect.Type, positional []positionalParam) *Schema {
+	optional := make(map[string]bool, len(positional))
+	for _, param := range positional {
+		if param.optional {
+			optional[param.name] = true
+		}
+	}
+	if len(optional) == 0 {
+		return nil
+	}
+	schema := SchemaFor(paramType)
+	required := make([]string, 0, len(schema.Required))
+	for _, name := range schema.Required {
+		if !optional[name] {
+			required = append(required, name)
+		}
+	}
+	schema.Required = required
+	return schema
+}
+
+// positionalParamNames returns names of params in order of positions
+func positionalParamNames(positional []positionalParam) []string {
+	names := make([]string, 0, len(positional))
+	for _, param := range positional {
+		names = append(names, param.name)
+	}
+	return names
+}
+
+// sortByPositions orders names so positional params come first in order of their positions
+func sortByPositions(names []string, positions []string) {
+	index := make(map[string]int, len(positions))
+	for i, name := range positions {
+		index[name] = i
+	}
+	sort.SliceStable(names, func(i, j int) bool {
+		a, aOk := index[names[i]]
+		b, bOk := index[names[j]]
+		if aOk && bOk {
+			return a < b
+		}
+		return aOk && !bOk
+	})
+}
@@ -0,0 +1,139 @@
+package jsonrpc2
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testPaymentParams struct {
+	From   string `json:"from" jsonrpc:"0"`
+	To     string `json:"to" jsonrpc:"1"`
+	Amount int64  `json:"amount,omitempty" jsonrpc:"2,optional"`
+}
+
+func TestGetPositionalParams(t *testing.T) {
+	assert := assert.New(t)
+	positional, err := getPositionalParams(reflect.TypeFor[*testPaymentParams]())
+	assert.Nil(err)
+	assert.Equal([]positionalParam{{name: "from"}, {name: "to"}, {name: "amount", optional: true}}, positional)
+
+	positional, err = getPositionalParams(reflect.TypeFor[testBlockQuery]())
+	assert.Nil(err)
+	assert.Nil(positional)
+
+	_, err = getPositionalParams(reflect.TypeFor[struct {
+		A string `jsonrpc:"0"`
+		B string `jsonrpc:"0"`
+	}]())
+	assert.NotNil(err)
+	_, err = getPositionalParams(reflect.TypeFor[struct {
+		A string `jsonrpc:"0"`
+		B string `jsonrpc:"2"`
+	}]())
+	assert.NotNil(err)
+	_, err = getPositionalParams(reflect.TypeFor[struct {
+		A string `json:"-" jsonrpc:"0"`
+	}]())
+	assert.NotNil(err)
+}
+
+func TestStreamPositionalParams(t *testing.T) {
+	assert := assert.New(t)
+	connA, connB := net.Pipe()
+	s := NewStreamEndpoint(context.Background(), NewPlainObjectStream(connA))
+	c := NewStreamEndpoint(context.Background(), NewPlainObjectStream(connB))
+	RegisterEndpointMethod(s, "transfer", func(ctx context.Context, p testPaymentParams) (testPaymentParams, *Error) {
+		return p, nil
+	}, WithStrictParams())
+
+	response, err := Request[[]interface{}, testPaymentParams](context.Background(), c, "transfer", []interface{}{"alice", "bob", 10})
+	assert.Nil(err)
+	result, err := response.Unwrap()
+	assert.Nil(err)
+	assert.Equal(testPaymentParams{From: "alice", To: "bob", Amount: 10}, result)
+
+	response, err = Request[[]interface{}, testPaymentParams](context.Background(), c, "transfer", []interface{}{"alice", "bob"})
+	assert.Nil(err)
+	result, err = response.Unwrap()
+	assert.Nil(err)
+	assert.Equal(testPaymentParams{From: "alice", To: "bob"}, result)
+
+	response, err = Request[testPaymentParams, testPaymentParams](context.Background(), c, "transfer", testPaymentParams{From: "alice", To: "bob", Amount: 3})
+	assert.Nil(err)
+	result, err = response.Unwrap()
+	assert.Nil(err)
+	assert.Equal(testPaymentParams{From: "alice", To: "bob", Amount: 3}, result)
+
+	response, err = Request[PositionalParams[testPaymentParams], testPaymentParams](context.Background(), c, "transfer", AsPositional(testPaymentParams{From: "alice", To: "bob", Amount: 3}))
+	assert.Nil(err)
+	result, err = response.Unwrap()
+	assert.Nil(err)
+	assert.Equal(testPaymentParams{From: "alice", To: "bob", Amount: 3}, result)
+
+	response, err = Request[[]interface{}, testPaymentParams](context.Background(), c, "transfer", []interface{}{"alice"})
+	assert.Nil(err)
+	if assert.NotNil(response.Error) {
+		assert.Equal(-32602, response.Error.Code)
+		var data ParamsDecodingError
+		assert.Nil(json.Unmarshal(*response.Error.Data, &data))
+		assert.Equal("to", data.Field)
+	}
+
+	response, err = Request[[]interface{}, testPaymentParams](context.Background(), c, "transfer", []interface{}{"alice", "bob", 1, 2})
+	assert.Nil(err)
+	if assert.NotNil(response.Error) {
+		assert.Equal(-32602, response.Error.Code)
+	}
+}
+
+func TestWithPositionalParams(t *testing.T) {
+	assert := assert.New(t)
+	reg := NewMethodRegistry()
+	RegisterMethod(reg, "sum", func(ctx context.Context, p map[string]int) (int, *Error) {
+		return p["a"] + p["b"], nil
+	}, WithPositionalParams("a", "b,optional"))
+	assert.Equal([]string{"a", "b"}, GetMethodInfo(reg, "sum").PositionalParams)
+
+	for params, expected := range map[string]string{`[1, 2]`: "3", `[1]`: "1", `{"a": 2, "b": 2}`: "4"} {
+		response, err := json.Marshal(ProcessRpcRequest(context.Background(), reg, &Message{Id: &ID{"1"}, Method: "sum", Params: json.RawMessage(params)}))
+		assert.Nil(err)
+		assert.JSONEq(`{"jsonrpc": "2.0", "id": "1", "result": `+expected+`}`, string(response))
+	}
+}
+
+func TestAsPositional(t *testing.T) {
+	assert := assert.New(t)
+	data, err := json.Marshal(NewRequest("1", "transfer", AsPositional(testPaymentParams{From: "alice", To: "bob"})))
+	assert.Nil(err)
+	assert.JSONEq(`{"jsonrpc": "2.0", "id": "1", "method": "transfer", "params": ["alice", "bob"]}`, string(data))
+
+	data, err = json.Marshal(AsPositional(testPaymentParams{From: "alice", Amount: 1}))
+	assert.Nil(err)
+	assert.JSONEq(`["alice", "", 1]`, string(data))
+
+	_, err = json.Marshal(AsPositional(testBlockQuery{}))
+	assert.NotNil(err)
+}
+
+func TestOptionalPositionalParamsValidation(t *testing.T) {
+	assert := assert.New(t)
+	reg := NewMethodRegistry()
+	RegisterMethod(reg, "sum", func(ctx context.Context, p struct {
+		A int `json:"a" jsonrpc:"0"`
+		B int `json:"b" jsonrpc:"1,optional"`
+	}) (int, *Error) {
+		return p.A + p.B, nil
+	}, WithParamsValidation())
+	assert.Equal([]string{"a"}, GetMethodInfo(reg, "sum").ParamsSchema.Required)
+
+	for params, expected := range map[string]string{`[1, 2]`: "3", `[1]`: "1"} {
+		response, err := json.Marshal(ProcessRpcRequest(context.Background(), reg, &Message{Id: &ID{"1"}, Method: "sum", Params: json.RawMessage(params)}))
+		assert.Nil(err)
+		assert.JSONEq(`{"jsonrpc": "2.0", "id": "1", "result": `+expected+`}`, string(response))
+	}
+}
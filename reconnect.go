@@ -0,0 +1,476 @@
+package jsonrpc2
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ErrConnectionLost is returned for requests which could not be completed because connection
+// of ReconnectingClient was lost, and for calls made while it is reconnecting
+var ErrConnectionLost = errors.New("connection lost")
+
+// DialFunc opens new stream to the peer
+type DialFunc func(ctx context.Context) (ObjectStream, error)
+
+// ConnectionState is state of the connection of ReconnectingClient
+type ConnectionState int
+
+const (
+	StateConnected ConnectionState = iota
+	StateDisconnected
+	StateReconnecting
+	// StateClosed is final state entered after Close, cancellation of the context
+	// or when reconnect attempts are exhausted
+	StateClosed
+)
+
+func (s ConnectionState) String() string {
+	switch s {
+	case StateConnected:
+		return "connected"
+	case StateDisconnected:
+		return "disconnected"
+	case StateReconnecting:
+		return "reconnecting"
+	case StateClosed:
+		return "closed"
+	}
+	return "unknown"
+}
+
+// ConnectionEvent describes change of the connection state
+type ConnectionEvent struct {
+	State ConnectionState
+	// Attempt is number of the reconnect attempt, 0 for the initial connection
+	Attempt int
+	// Err is reason of the disconnect or error of the last failed dial
+	Err error
+}
+
+// ReconnectOptions configures ReconnectingClient
+type ReconnectOptions struct {
+	// InitialBackoff is delay before the first reconnect attempt, 100ms if 0
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between attempts, 30s if 0
+	MaxBackoff time.Duration
+	// Multiplier the delay grows by after every failed attempt, 2 if less than 1
+	Multiplier float64
+	// MaxAttempts is number of consecutive failed attempts after which the client is closed, 0 means unlimited
+	MaxAttempts int
+	// ReplayPending resends requests awaiting response after reconnect. Otherwise they fail with ErrConnectionLost.
+	ReplayPending bool
+	// Validation is validation mode of the client and of every endpoint
+	Validation ValidationMode
+	// ProtocolVersion is protocol version of the client and of every endpoint
+	ProtocolVersion ProtocolVersion
+	// OnConnect is invoked for every new endpoint before it starts reading messages,
+	// e.g. to set up middlewares or keepalive
+	OnConnect func(ctx context.Context, endpoint *StreamEndpoint)
+	// OnStateChange is invoked on every change of the connection state, it must not block
+	OnStateChange func(event ConnectionEvent)
+}
+
+// ReconnectingClient is client endpoint which redials the peer when the connection is lost.
+// Methods registered on the client are served on every connection.
+type ReconnectingClient struct {
+	clientConfig
+	// protocol is set from options, so every endpoint speaks the same protocol from the first message
+	protocol protocolConfig
+
+	ctx     context.Context
+	dial    DialFunc
+	options ReconnectOptions
+
+	mutex       sync.Mutex
+	endpoint    *StreamEndpoint
+	state       ConnectionState
+	closed      bool
+	closeNotify chan struct{}
+	pending     map[string]*reconnectingRequest
+	// writes counts written objects, so requests are replayed in the order they were written
+	writes uint64
+
+	logger         *slog.Logger
+	methodRegistry RpcMethodRegistry
+}
+
+// reconnectingRequest is request awaiting response, it outlives endpoints it was sent through
+type reconnectingRequest struct {
+	id       interface{}
+	response chan Message
+	// object the request was written in, nil if it was not written yet
+	written *writtenObject
+	// err is set when the request failed, response channel is closed then
+	err error
+	// done is closed when the request is unregistered or failed
+	done chan struct{}
+}
+
+type writtenObject struct {
+	obj interface{}
+	seq uint64
+}
+
+// DialReconnecting connects to the peer with dial and returns client which redials it with
+// exponential backoff whenever the connection is lost. Error is returned if the initial dial fails.
+// Cancelling the context closes the client.
+func DialReconnecting(ctx context.Context, dial DialFunc, options ReconnectOptions) (*ReconnectingClient, error) {
+	if options.InitialBackoff <= 0 {
+		options.InitialBackoff = 100 * time.Millisecond
+	}
+	if options.MaxBackoff <= 0 {
+		options.MaxBackoff = 30 * time.Second
+	}
+	if options.Multiplier < 1 {
+		options.Multiplier = 2
+	}
+	stream, err := dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &ReconnectingClient{
+		ctx:            ctx,
+		dial:           dial,
+		options:        options,
+		protocol:       protocolConfig{validation: options.Validation, version: options.ProtocolVersion},
+		state:          StateDisconnected,
+		closeNotify:    make(chan struct{}),
+		pending:        make(map[string]*reconnectingRequest),
+		logger:         slog.Default(),
+		methodRegistry: NewMethodRegistry(),
+	}
+	r.connect(stream, 0)
+	go r.run()
+	return r, nil
+}
+
+func (r *ReconnectingClient) GetMethods() RpcMethodRegistry {
+	return r.methodRegistry
+}
+
+func (r *ReconnectingClient) UseLogger(logger *slog.Logger) {
+	if logger == nil {
+		r.logger.Debug("ignored nil logger")
+		return
+	}
+	r.logger = logger
+}
+
+func (r *ReconnectingClient) getProtocolConfig() *protocolConfig {
+	return &r.protocol
+}
+
+// State returns current state of the connection
+func (r *ReconnectingClient) State() ConnectionState {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.state
+}
+
+// Endpoint returns endpoint of the current connection, nil while disconnected
+func (r *ReconnectingClient) Endpoint() *StreamEndpoint {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.endpoint
+}
+
+// returns a channel that will be closed when the client is closed for good
+func (r *ReconnectingClient) GetOnCloseListener() <-chan struct{} {
+	return r.closeNotify
+}
+
+func (r *ReconnectingClient) setState(state ConnectionState, attempt int, err error) {
+	r.mutex.Lock()
+	r.state = state
+	r.mutex.Unlock()
+	r.logger.Debug("jsonrpc2: connection state changed", "state", state, "attempt", attempt, "error", err)
+	if r.options.OnStateChange != nil {
+		r.options.OnStateChange(ConnectionEvent{State: state, Attempt: attempt, Err: err})
+	}
+}
+
+// connect starts endpoint on the stream and sends requests kept for replay through it.
+// Returns false if the client was closed meanwhile.
+func (r *ReconnectingClient) connect(stream ObjectStream, attempt int) bool {
+	endpoint := newStreamEndpoint(stream)
+	endpoint.methodRegistry = r.methodRegistry
+	endpoint.protocolConfig = r.protocol
+	endpoint.UseLogger(r.logger)
+	if r.options.OnConnect != nil {
+		r.options.OnConnect(r.ctx, endpoint)
+	}
+
+	r.mutex.Lock()
+	if r.closed {
+		r.mutex.Unlock()
+		stream.Close()
+		return false
+	}
+	r.endpoint = endpoint
+	replay := make([]*writtenObject, 0)
+	replayed := make(map[*writtenObject]struct{})
+	for _, p := range r.pending {
+		if p.err != nil {
+			continue
+		}
+		r.forward(endpoint, p)
+		if p.written == nil {
+			continue
+		}
+		if _, ok := replayed[p.written]; !ok {
+			replayed[p.written] = struct{}{}
+			replay = append(replay, p.written)
+		}
+	}
+	r.mutex.Unlock()
+	slices.SortFunc(replay, func(a, b *writtenObject) int {
+		return cmp.Compare(a.seq, b.seq)
+	})
+
+	endpoint.start(r.ctx)
+	r.setState(StateConnected, attempt, nil)
+	for _, written := range replay {
+		if err := endpoint.WriteObject(written.obj); err != nil {
+			r.logger.Debug("jsonrpc2: failed to replay request", "error", err)
+			break
+		}
+	}
+	return true
+}
+
+// run waits for the connection to be lost and redials the peer
+func (r *ReconnectingClient) run() {
+	for {
+		endpoint := r.Endpoint()
+		select {
+		case <-endpoint.GetOnCloseListener():
+		case <-r.ctx.Done():
+			r.Close()
+			return
+		}
+
+		r.mutex.Lock()
+		if r.closed {
+			r.mutex.Unlock()
+			return
+		}
+		r.endpoint = nil
+		for _, p := range r.pending {
+			if p.written != nil && !r.options.ReplayPending {
+				r.failRequest(p, ErrConnectionLost)
+			}
+		}
+		r.mutex.Unlock()
+		r.setState(StateDisconnected, 0, endpoint.CloseCause())
+
+		if err := r.reconnect(); err != nil {
+			r.shutdown(err)
+			return
+		}
+	}
+}
+
+// reconnect dials the peer until it succeeds or attempts are exhausted
+func (r *ReconnectingClient) reconnect() error {
+	backoff := r.options.InitialBackoff
+	var err error
+	for attempt := 1; r.options.MaxAttempts <= 0 || attempt <= r.options.MaxAttempts; attempt++ {
+		r.setState(StateReconnecting, attempt, err)
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-r.closeNotify:
+			timer.Stop()
+			return ErrStreamClosed
+		case <-r.ctx.Done():
+			timer.Stop()
+			return r.ctx.Err()
+		}
+
+		var stream ObjectStream
+		if stream, err = r.dial(r.ctx); err == nil {
+			if !r.connect(stream, attempt) {
+				return ErrStreamClosed
+			}
+			return nil
+		}
+		r.logger.Debug("jsonrpc2: reconnect failed", "attempt", attempt, "error", err)
+		backoff = min(time.Duration(float64(backoff)*r.options.Multiplier), r.options.MaxBackoff)
+	}
+	return err
+}
+
+// shutdown closes the client after reconnecting failed
+func (r *ReconnectingClient) shutdown(cause error) {
+	r.mutex.Lock()
+	if r.closed {
+		r.mutex.Unlock()
+		return
+	}
+	r.closed = true
+	close(r.closeNotify)
+	for _, p := range r.pending {
+		r.failRequest(p, ErrConnectionLost)
+	}
+	r.mutex.Unlock()
+	r.setState(StateClosed, 0, cause)
+}
+
+// Close closes the client and its current connection. Pending requests fail with ErrStreamClosed.
+func (r *ReconnectingClient) Close() error {
+	r.mutex.Lock()
+	if r.closed {
+		r.mutex.Unlock()
+		return ErrStreamClosed
+	}
+	r.closed = true
+	close(r.closeNotify)
+	for _, p := range r.pending {
+		r.failRequest(p, ErrStreamClosed)
+	}
+	endpoint := r.endpoint
+	r.mutex.Unlock()
+
+	r.setState(StateClosed, 0, nil)
+	if endpoint != nil {
+		return endpoint.Close()
+	}
+	return nil
+}
+
+func (r *ReconnectingClient) IsClosed() bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.closed
+}
+
+// failRequest resolves the request with the error, must be called with mutex held
+func (r *ReconnectingClient) failRequest(p *reconnectingRequest, err error) {
+	if p.err != nil {
+		return
+	}
+	p.err = err
+	close(p.done)
+	close(p.response)
+}
+
+// forward passes response to the request received by the endpoint, must be called with mutex held
+func (r *ReconnectingClient) forward(endpoint *StreamEndpoint, p *reconnectingRequest) {
+	responses := endpoint.RegisterPendingRequest(p.id)
+	go func() {
+		defer endpoint.UnregisterPendingRequest(p.id)
+		select {
+		case response, ok := <-responses:
+			if !ok {
+				return
+			}
+			r.mutex.Lock()
+			defer r.mutex.Unlock()
+			if r.pending[idKey(p.id)] == p && p.err == nil {
+				select {
+				case p.response <- response:
+				default:
+				}
+			}
+		case <-p.done:
+		}
+	}()
+}
+
+func (r *ReconnectingClient) WriteObject(obj interface{}) error {
+	r.mutex.Lock()
+	if r.closed {
+		r.mutex.Unlock()
+		return ErrStreamClosed
+	}
+	endpoint := r.endpoint
+	if endpoint == nil {
+		r.mutex.Unlock()
+		return ErrConnectionLost
+	}
+	r.writes++
+	written := &writtenObject{obj: obj, seq: r.writes}
+	ids := requestIds(obj)
+	r.setWritten(ids, written)
+	r.mutex.Unlock()
+
+	if err := endpoint.WriteObject(obj); err != nil {
+		r.mutex.Lock()
+		r.setWritten(ids, nil)
+		r.mutex.Unlock()
+		if endpoint.IsClosed() {
+			return ErrConnectionLost
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *ReconnectingClient) setWritten(ids []interface{}, written *writtenObject) {
+	for _, id := range ids {
+		if p, ok := r.pending[idKey(id)]; ok {
+			p.written = written
+		}
+	}
+}
+
+func (r *ReconnectingClient) RegisterPendingRequest(requestId interface{}) <-chan Message {
+	p := &reconnectingRequest{id: requestId, response: make(chan Message, 1), done: make(chan struct{})}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.closed {
+		p.err = ErrStreamClosed
+		close(p.done)
+		close(p.response)
+	} else if r.endpoint != nil {
+		r.forward(r.endpoint, p)
+	}
+	r.pending[idKey(requestId)] = p
+	return p.response
+}
+
+func (r *ReconnectingClient) UnregisterPendingRequest(requestId interface{}) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if p, ok := r.pending[idKey(requestId)]; ok {
+		delete(r.pending, idKey(requestId))
+		if p.err == nil {
+			close(p.done)
+		}
+	}
+}
+
+// pendingError returns error the pending request failed with
+func (r *ReconnectingClient) pendingError(requestId interface{}) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if p, ok := r.pending[idKey(requestId)]; ok && p.err != nil {
+		return p.err
+	}
+	return ErrStreamClosed
+}
+
+// requestIds returns ids of requests in object written by client calls
+func requestIds(obj interface{}) []interface{} {
+	switch obj := obj.(type) {
+	case *request[interface{}]:
+		if obj.Id != nil {
+			return []interface{}{obj.Id}
+		}
+	case []*request[interface{}]:
+		ids := make([]interface{}, 0, len(obj))
+		for _, rpcRequest := range obj {
+			if rpcRequest.Id != nil {
+				ids = append(ids, rpcRequest.Id)
+			}
+		}
+		return ids
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+package jsonrpc2
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// pipeDialer returns dial function handing server side of every connection to serve
+func pipeDialer(serve func(s *StreamEndpoint)) (DialFunc, chan *StreamEndpoint) {
+	servers := make(chan *StreamEndpoint, 10)
+	return func(ctx context.Context) (ObjectStream, error) {
+		connA, connB := net.Pipe()
+		s := NewStreamEndpoint(context.Background(), NewPlainObjectStream(connA), serve)
+		servers <- s
+		return NewPlainObjectStream(connB), nil
+	}, servers
+}
+
+func TestReconnectingClient(t *testing.T) {
+	assert := assert.New(t)
+	dial, servers := pipeDialer(func(s *StreamEndpoint) {
+		RegisterEndpointMethod(s, "hello", func(ctx context.Context, name string) (string, *Error) {
+			return "Hello " + name, nil
+		})
+	})
+	events := make(chan ConnectionEvent, 10)
+	c, err := DialReconnecting(context.Background(), dial, ReconnectOptions{
+		InitialBackoff: 10 * time.Millisecond,
+		OnStateChange:  func(event ConnectionEvent) { events <- event },
+	})
+	assert.Nil(err)
+	defer c.Close()
+	RegisterEndpointMethod(c, "name", func(ctx context.Context, _ string) (string, *Error) {
+		return "client", nil
+	})
+	assert.Equal(StateConnected, (<-events).State)
+
+	response, err := Request[[]string, string](context.Background(), c, "hello", []string{"World"})
+	assert.Nil(err)
+	assert.Equal("Hello World", response.Result)
+
+	(<-servers).Close()
+	assert.Equal(StateDisconnected, (<-events).State)
+	assert.Equal(ConnectionEvent{State: StateReconnecting, Attempt: 1}, <-events)
+	assert.Equal(ConnectionEvent{State: StateConnected, Attempt: 1}, <-events)
+	assert.Equal(StateConnected, c.State())
+
+	response, err = Request[[]string, string](context.Background(), c, "hello", []string{"World"})
+	assert.Nil(err)
+	assert.Equal("Hello World", response.Result)
+	// methods of the client are served on the new connection
+	nameResponse, err := Request[[]string, string](context.Background(), <-servers, "name", []string{""})
+	assert.Nil(err)
+	assert.Equal("client", nameResponse.Result)
+
+	assert.Nil(c.Close())
+	assert.Equal(StateClosed, (<-events).State)
+	_, err = Request[[]string, string](context.Background(), c, "hello", []string{"World"})
+	assert.ErrorIs(err, ErrStreamClosed)
+}
+
+func TestReconnectingClientFailsPending(t *testing.T) {
+	assert := assert.New(t)
+	dial, _ := pipeDialer(func(s *StreamEndpoint) {
+		RegisterEndpointMethod(s, "close", func(ctx context.Context, name string) (string, *Error) {
+			EndpointFromContext(ctx).Close()
+			return "", nil
+		})
+	})
+	c, err := DialReconnecting(context.Background(), dial, ReconnectOptions{InitialBackoff: 10 * time.Millisecond})
+	assert.Nil(err)
+	defer c.Close()
+
+	_, err = Request[[]string, string](context.Background(), c, "close", []string{"World"})
+	assert.ErrorIs(err, ErrConnectionLost)
+}
+
+func TestReconnectingClientReplaysPending(t *testing.T) {
+	assert := assert.New(t)
+	connections := 0
+	dial, _ := pipeDialer(func(s *StreamEndpoint) {
+		connections++
+		first := connections == 1
+		RegisterEndpointMethod(s, "hello", func(ctx context.Context, name string) (string, *Error) {
+			if first {
+				s.Close()
+				return "", nil
+			}
+			return "Hello " + name, nil
+		})
+	})
+	c, err := DialReconnecting(context.Background(), dial, ReconnectOptions{InitialBackoff: 10 * time.Millisecond, ReplayPending: true})
+	assert.Nil(err)
+	defer c.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	response, err := Request[[]string, string](ctx, c, "hello", []string{"World"})
+	assert.Nil(err)
+	assert.Equal("Hello World", response.Result)
+}
+
+func TestReconnectingClientReplayOrder(t *testing.T) {
+	assert := assert.New(t)
+	release := make(chan struct{})
+	defer close(release)
+	var mutex sync.Mutex
+	received := make([]int, 0, 20)
+	connections := 0
+	dial, servers := pipeDialer(func(s *StreamEndpoint) {
+		connections++
+		first := connections == 1
+		s.UseOrdering(SequentialOrder())
+		RegisterEndpointMethod(s, "echo", func(ctx context.Context, n int) (int, *Error) {
+			if first {
+				<-release
+				return 0, nil
+			}
+			mutex.Lock()
+			defer mutex.Unlock()
+			received = append(received, n)
+			return n, nil
+		})
+	})
+	c, err := DialReconnecting(context.Background(), dial, ReconnectOptions{InitialBackoff: 10 * time.Millisecond, ReplayPending: true})
+	assert.Nil(err)
+	defer c.Close()
+
+	calls := make([]*Call[int], 0, 20)
+	expected := make([]int, 0, 20)
+	for i := 0; i < 20; i++ {
+		calls = append(calls, Go[[]int, int](context.Background(), c, "echo", []int{i}))
+		expected = append(expected, i)
+	}
+	(<-servers).Close()
+
+	for i, call := range calls {
+		response, err := call.Wait(context.Background())
+		assert.Nil(err)
+		assert.Equal(i, response.Result)
+	}
+	// requests are replayed in the order they were written
+	assert.Equal(expected, received)
+}
+
+func TestReconnectingClientMaxAttempts(t *testing.T) {
+	assert := assert.New(t)
+	errDial := errors.New("refused")
+	serverDial, servers := pipeDialer(func(s *StreamEndpoint) {})
+	dials := 0
+	dial := func(ctx context.Context) (ObjectStream, error) {
+		dials++
+		if dials > 1 {
+			return nil, errDial
+		}
+		return serverDial(ctx)
+	}
+	events := make(chan ConnectionEvent, 10)
+	c, err := DialReconnecting(context.Background(), dial, ReconnectOptions{
+		InitialBackoff: time.Millisecond,
+		MaxAttempts:    3,
+		OnStateChange:  func(event ConnectionEvent) { events <- event },
+	})
+	assert.Nil(err)
+
+	(<-servers).Close()
+	select {
+	case <-c.GetOnCloseListener():
+	case <-time.After(time.Second):
+		t.Fatal("client not closed")
+	}
+	assert.True(c.IsClosed())
+	assert.Equal(ConnectionEvent{State: StateConnected}, <-events)
+	assert.Equal(StateDisconnected, (<-events).State)
+	assert.Equal(ConnectionEvent{State: StateReconnecting, Attempt: 1}, <-events)
+	assert.Equal(ConnectionEvent{State: StateReconnecting, Attempt: 2, Err: errDial}, <-events)
+	assert.Equal(ConnectionEvent{State: StateReconnecting, Attempt: 3, Err: errDial}, <-events)
+	assert.Equal(ConnectionEvent{State: StateClosed, Err: errDial}, <-events)
+	assert.Equal(4, dials)
+}
+
+func TestReconnectingClientProtocolOptions(t *testing.T) {
+	assert := assert.New(t)
+	dial, _ := pipeDialer(func(s *StreamEndpoint) {
+		s.UseProtocolVersion(JsonRpc10)
+		s.UseValidation(LenientValidation)
+		RegisterEndpointMethod(s, "hello", func(ctx context.Context, name string) (string, *Error) {
+			return "Hello " + name, nil
+		})
+	})
+	c, err := DialReconnecting(context.Background(), dial, ReconnectOptions{Validation: LenientValidation, ProtocolVersion: JsonRpc10})
+	assert.Nil(err)
+	defer c.Close()
+	assert.True(c.Endpoint().isLegacy())
+	assert.False(c.Endpoint().isStrict())
+	assert.False(isStrictEndpoint(c))
+
+	response, err := Request[[]string, string](context.Background(), c, "hello", []string{"World"})
+	assert.Nil(err)
+	assert.Equal("Hello World", response.Result)
+}
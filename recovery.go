@@ -0,0 +1,88 @@
+package jsonrpc2
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+)
+
+// PanicHook is called with value recovered from panicking handler and stack trace of the panic.
+type PanicHook func(ctx context.Context, method string, recovered interface{}, stack []byte)
+
+// PanicData is data of internal error returned instead of response of panicking handler
+// when stack traces are enabled
+type PanicData struct {
+	Panic string `json:"panic"`
+	Stack string `json:"stack"`
+}
+
+// UsePanicStackTrace includes recovered value and stack trace in data of internal error
+// returned when handler panics. Meant for debugging, it exposes internals to the peer.
+func (c *serverConfig) UsePanicStackTrace() {
+	c.panicStack = true
+}
+
+// OnPanic sets hook called when handler panics, e.g. to report the panic.
+func (c *serverConfig) OnPanic(hook PanicHook) {
+	c.panicHook = hook
+}
+
+// recoverPanic converts panic of the handler to internal error response, nil for notifications
+func (c *serverConfig) recoverPanic(ctx context.Context, rpcMsg *Message, recovered interface{}, logger *slog.Logger) interface{} {
+	return c.panicResponse(ctx, rpcMsg, recovered, debug.Stack(), logger)
+}
+
+// panicResponse reports panic of the handler and converts it to internal error response, nil for notifications
+func (c *serverConfig) panicResponse(ctx context.Context, rpcMsg *Message, recovered interface{}, stack []byte, logger *slog.Logger) interface{} {
+	c.reportPanic(ctx, rpcMsg, recovered, stack, logger)
+	if rpcMsg.Id == nil {
+		return nil
+	}
+	if c != nil && c.panicStack {
+		return NewInternalErrorWithData(PanicData{Panic: fmt.Sprint(recovered), Stack: string(stack)}).ToResponse(rpcMsg.Id)
+	}
+	return NewInternalError().ToResponse(rpcMsg.Id)
+}
+
+// reportPanic logs panic of the handler and passes it to the panic hook
+func (c *serverConfig) reportPanic(ctx context.Context, rpcMsg *Message, recovered interface{}, stack []byte, logger *slog.Logger) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	logger.Error("jsonrpc2: handler panicked", "method", rpcMsg.Method, "request_id", rpcMsg.Id, "panic", recovered, "stack", string(stack))
+	if c != nil && c.panicHook != nil {
+		c.panicHook(ctx, rpcMsg.Method, recovered, stack)
+	}
+}
+
+// panicRecovery reports panics of handlers which are not recovered by processRpcRequest,
+// e.g. panics of handlers which outlived their deadline
+type panicRecovery struct {
+	config *serverConfig
+	logger *slog.Logger
+}
+
+func withPanicRecovery(ctx context.Context, config *serverConfig, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, panicRecoveryContextKey, &panicRecovery{config: config, logger: logger})
+}
+
+func getPanicRecovery(ctx context.Context) *panicRecovery {
+	if recovery, _ := ctx.Value(panicRecoveryContextKey).(*panicRecovery); recovery != nil {
+		return recovery
+	}
+	return &panicRecovery{}
+}
+
+// recoverHandlerPanic converts panic of the handler run in another goroutine to internal error
+// response of the endpoint the request was received on, stack is the stack of the handler
+func recoverHandlerPanic(ctx context.Context, rpcMsg *Message, recovered interface{}, stack []byte) interface{} {
+	recovery := getPanicRecovery(ctx)
+	return recovery.config.panicResponse(ctx, rpcMsg, recovered, stack, recovery.logger)
+}
+
+// reportLatePanic reports panic of the handler through the endpoint the request was received on
+func reportLatePanic(ctx context.Context, rpcMsg *Message, recovered interface{}, stack []byte) {
+	recovery := getPanicRecovery(ctx)
+	recovery.config.reportPanic(ctx, rpcMsg, recovered, stack, recovery.logger)
+}
@@ -0,0 +1,88 @@
+package jsonrpc2
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func panickingMethod(ctx context.Context, name string) (string, *Error) {
+	panic("boom")
+}
+
+func TestProcessRpcRequestRecovery(t *testing.T) {
+	assert := assert.New(t)
+	reg := NewMethodRegistry()
+	RegisterMethod(reg, "panic", panickingMethod)
+
+	response, err := json.Marshal(ProcessRpcRequest(context.Background(), reg, &Message{Id: &ID{"1"}, Method: "panic"}))
+	assert.Nil(err)
+	assert.JSONEq(`{"jsonrpc": "2.0", "id": "1", "error": {"code": -32603, "message": "Internal error"}}`, string(response))
+	assert.Nil(ProcessRpcRequest(context.Background(), reg, &Message{Method: "panic"}))
+}
+
+func TestStreamRecovery(t *testing.T) {
+	assert := assert.New(t)
+	connA, connB := net.Pipe()
+	s := NewStreamEndpoint(context.Background(), NewPlainObjectStream(connA))
+	s.UseLogger(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	c := NewStreamEndpoint(context.Background(), NewPlainObjectStream(connB))
+	RegisterEndpointMethod(s, "panic", panickingMethod)
+	panics := make(chan interface{}, 2)
+	s.OnPanic(func(ctx context.Context, method string, recovered interface{}, stack []byte) {
+		assert.Equal("panic", method)
+		assert.Equal(method, MethodFromContext(ctx))
+		assert.NotEmpty(stack)
+		panics <- recovered
+	})
+
+	response, err := Request[[]string, string](context.Background(), c, "panic", []string{"World"})
+	assert.Nil(err)
+	if assert.NotNil(response.Error) {
+		assert.Equal(-32603, response.Error.Code)
+		assert.Nil(response.Error.Data)
+	}
+	assert.Equal("boom", <-panics)
+
+	assert.Nil(Notify(context.Background(), c, "panic", []string{"World"}))
+	assert.Equal("boom", <-panics)
+
+	s.UsePanicStackTrace()
+	response, err = Request[[]string, string](context.Background(), c, "panic", []string{"World"})
+	assert.Nil(err)
+	if assert.NotNil(response.Error) && assert.NotNil(response.Error.Data) {
+		var data PanicData
+		assert.Nil(json.Unmarshal(*response.Error.Data, &data))
+		assert.Equal("boom", data.Panic)
+		assert.Contains(data.Stack, "panickingMethod")
+	}
+	<-panics
+}
+
+func TestHttpRecovery(t *testing.T) {
+	assert := assert.New(t)
+	mux := NewServerMux()
+	mux.UseLogger(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	RegisterServerMuxEndpointMethod(mux, "/", "panic", panickingMethod)
+	RegisterServerMuxEndpointMethod(mux, "/", "hello", func(ctx context.Context, name string) (string, *Error) {
+		return "Hello " + name, nil
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	c := NewHttpClientEndpoint(srv.URL, nil)
+
+	response, err := Request[[]string, string](context.Background(), c, "panic", []string{"World"})
+	assert.Nil(err)
+	if assert.NotNil(response.Error) {
+		assert.Equal(-32603, response.Error.Code)
+	}
+	response, err = Request[[]string, string](context.Background(), c, "hello", []string{"World"})
+	assert.Nil(err)
+	assert.Equal("Hello World", response.Result)
+}
@@ -2,17 +2,20 @@ package jsonrpc2
 
 import (
 	"context"
+	"log/slog"
+	"reflect"
+	"time"
 )
 
 type RpcMethod[TParam Params, TResult Result] func(ctx context.Context, p TParam) (TResult, *Error)
-type RpcHandler func(ctx context.Context, rpcMessage *message) interface{}
+type RpcHandler func(ctx context.Context, rpcMessage *Message) interface{}
 type RpcMethodRegistry map[string]RpcHandler
 
 func NewMethodRegistry() RpcMethodRegistry {
 	return make(RpcMethodRegistry)
 }
 
-func getMethodHandler(reg RpcMethodRegistry, rpcMsg *message) (RpcHandler, *errorResponse) {
+func getMethodHandler(reg RpcMethodRegistry, rpcMsg *Message) (RpcHandler, *errorResponse) {
 	if rpcMsg == nil {
 		return nil, NewInvalidRequestWithData(ErrInternalInvalidJsonRpcMessage.Error()).ToResponse(nil)
 	}
@@ -26,17 +29,126 @@ func getMethodHandler(reg RpcMethodRegistry, rpcMsg *message) (RpcHandler, *erro
 	return handler, nil
 }
 
-func ProcessRpcRequest(ctx context.Context, reg RpcMethodRegistry, rpcMsg *message) interface{} {
+// serverConfig holds server side configuration shared by server endpoints
+type serverConfig struct {
+	// include panic value and stack in internal error data
+	panicStack bool
+	panicHook  PanicHook
+	// honor deadlines sent by clients in meta of requests
+	clientDeadlines bool
+}
+
+// ProcessRpcRequest dispatches message to the method handler. Panic of the handler
+// is logged with default logger and converted to internal error.
+func ProcessRpcRequest(ctx context.Context, reg RpcMethodRegistry, rpcMsg *Message) interface{} {
+	return processRpcRequest(ctx, reg, rpcMsg, nil, nil, nil)
+}
+
+// processRpcRequest dispatches message to the method handler wrapped in endpoint middlewares
+func processRpcRequest(ctx context.Context, reg RpcMethodRegistry, rpcMsg *Message, middlewares []RpcMiddleware, config *serverConfig, logger *slog.Logger) (response interface{}) {
 	handler, errResponse := getMethodHandler(reg, rpcMsg)
 	if errResponse != nil {
 		return errResponse
 	}
+	ctx = withRequestContext(ctx, rpcMsg)
+	ctx = withPanicRecovery(ctx, config, logger)
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			response = config.recoverPanic(ctx, rpcMsg, recovered, logger)
+		}
+	}()
+	handler = ChainMiddleware(handler, middlewares...)
+	if config != nil && config.clientDeadlines {
+		if deadline, ok := getClientDeadline(rpcMsg); ok {
+			ctx, cancel := context.WithDeadline(ctx, deadline)
+			defer cancel()
+			return runWithDeadline(ctx, rpcMsg, handler, getTimeoutCode(reg, rpcMsg.Method))
+		}
+	}
 	return handler(ctx, rpcMsg)
 }
 
-func RegisterMethod[TParam Params, TResult Result](reg RpcMethodRegistry, method string, handler RpcMethod[TParam, TResult]) {
-	reg[method] = func(ctx context.Context, rpcMsg *message) interface{} {
-		request := MessageToRequest[TParam](rpcMsg)
+type methodOptions struct {
+	middlewares    []RpcMiddleware
+	info           MethodInfo
+	validateParams bool
+	strictParams   bool
+	timeout        time.Duration
+	timeoutCode    int
+	// nil if positions are taken from jsonrpc tags
+	positionalParams []positionalParam
+}
+
+// MethodOption configures method registered with RegisterMethod
+type MethodOption func(*methodOptions)
+
+// WithMiddleware wraps the method handler in middlewares, first one is the outermost.
+func WithMiddleware(middlewares ...RpcMiddleware) MethodOption {
+	return func(o *methodOptions) {
+		o.middlewares = append(o.middlewares, middlewares...)
+	}
+}
+
+func newMethodOptions(opts []MethodOption) *methodOptions {
+	options := &methodOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	return options
+}
+
+// getPositionalParams returns positions of params set by option or by jsonrpc tags of the param type
+func (o *methodOptions) getPositionalParams(paramType reflect.Type) ([]positionalParam, error) {
+	if o.positionalParams != nil {
+		return o.positionalParams, nil
+	}
+	return getPositionalParams(paramType)
+}
+
+// registerHandler registers handler under the method with method options applied
+func registerHandler(reg RpcMethodRegistry, method string, paramType, resultType reflect.Type, handler RpcHandler, options *methodOptions) error {
+	positional, err := options.getPositionalParams(paramType)
+	if err != nil {
+		return err
+	}
+
+	info := options.info
+	info.Name = method
+	info.ParamType = paramType
+	info.ResultType = resultType
+	info.PositionalParams = positionalParamNames(positional)
+	if info.ParamsSchema == nil {
+		info.ParamsSchema = optionalParamsSchema(paramType, positional)
+	}
+	if options.validateParams {
+		schema := info.ParamsSchema
+		if schema == nil {
+			schema = SchemaFor(paramType)
+		}
+		handler = withParamsValidation(schema, handler)
+	}
+	if isScalarParamType(paramType) {
+		handler = withScalarParams(handler)
+	}
+	if positional != nil {
+		handler = withPositionalParams(positional, handler)
+	}
+	if options.timeout > 0 {
+		handler = withTimeout(options.timeout, options.timeoutCode, handler)
+	}
+	reg[method] = ChainMiddleware(handler, options.middlewares...)
+	getMethodTable(reg, true).set(method, &methodEntry{info: &info, timeoutCode: options.timeoutCode})
+	return nil
+}
+
+// RegisterMethod registers handler of the method. Panics if param type has invalid jsonrpc tags.
+func RegisterMethod[TParam Params, TResult Result](reg RpcMethodRegistry, method string, handler RpcMethod[TParam, TResult], opts ...MethodOption) {
+	options := newMethodOptions(opts)
+	err := registerHandler(reg, method, reflect.TypeFor[TParam](), reflect.TypeFor[TResult](), func(ctx context.Context, rpcMsg *Message) interface{} {
+		request, jsonRpcErr := decodeRequest[TParam](rpcMsg, options.strictParams)
+		if jsonRpcErr != nil {
+			return jsonRpcErr.ToResponse(rpcMsg.Id)
+		}
 		result, jsonRpcErr := handler(ctx, request.Params)
 		if jsonRpcErr != nil {
 			response := jsonRpcErr.ToResponse(request.Id)
@@ -44,5 +156,8 @@ func RegisterMethod[TParam Params, TResult Result](reg RpcMethodRegistry, method
 		}
 		response := NewSuccessResponseI(request.Id, result)
 		return response
+	}, options)
+	if err != nil {
+		panic(err)
 	}
 }
@@ -14,7 +14,7 @@ func TestGetMethodHandler(t *testing.T) {
 
 	_, e := getMethodHandler(reg, nil)
 	assert.Contains(string(*e.Error.Data), ErrInternalInvalidJsonRpcMessage.Error())
-	_, e = getMethodHandler(reg, &message{})
+	_, e = getMethodHandler(reg, &Message{})
 	assert.Contains(string(*e.Error.Data), ErrInternalNotRequest.Error())
 }
 
@@ -28,7 +28,7 @@ func TestRegisterMethod(t *testing.T) {
 	handler, ok := reg["test"]
 	assert.True(ok)
 	var p json.RawMessage = []byte("\"test\"")
-	r := handler(context.Background(), &message{
+	r := handler(context.Background(), &Message{
 		Params: p,
 	})
 	_, ok = r.(*errorResponse)
@@ -2,9 +2,10 @@ package jsonrpc2
 
 type request[TParam Params] struct {
 	messageBase
-	Id     interface{} `json:"id,omitempty"`
-	Method string      `json:"method,omitempty"`
-	Params TParam      `json:"params,omitempty"`
+	Id     interface{}            `json:"id,omitempty"`
+	Method string                 `json:"method,omitempty"`
+	Params TParam                 `json:"params,omitempty"`
+	Meta   map[string]interface{} `json:"meta,omitempty"`
 }
 
 func NewRequest[TId Id, TParam Params](id TId, method string, params TParam) (req *request[TParam]) {
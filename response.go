@@ -1,6 +1,7 @@
 package jsonrpc2
 
 import (
+	"encoding/json"
 	"fmt"
 )
 
@@ -25,6 +26,15 @@ func NewResponse[TResult Result](id interface{}, result TResult, err *ErrorObj)
 	return NewResponseI(id, zero, nil)
 }
 
+// MarshalJSON encodes response, result of success response is always present even if it is null,
+// error response carries no result
+func (r Response[TResult]) MarshalJSON() ([]byte, error) {
+	if r.IsError() {
+		return json.Marshal(errorResponse{r.messageBase, r.Id, nil, r.Error})
+	}
+	return json.Marshal(successResponse[TResult](r))
+}
+
 func (r *Response[TResult]) IsSuccess() bool {
 	return r.Error == nil
 }
@@ -56,6 +66,14 @@ func NewSuccessResponseI[TResult Result](id interface{}, result TResult) *succes
 	}
 }
 
+func (r successResponse[TResult]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		messageBase
+		Id     interface{} `json:"id"`
+		Result TResult     `json:"result"`
+	}{r.messageBase, r.Id, r.Result})
+}
+
 func NewSuccessResponse[TId Id, TResult Result](id TId, result TResult) *successResponse[TResult] {
 	return NewSuccessResponseI((interface{})(id), result)
 }
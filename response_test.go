@@ -0,0 +1,92 @@
+package jsonrpc2
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResponseMarshal(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		response interface{}
+		expected string
+	}{
+		{NewSuccessResponseI[interface{}](1, nil), `{"jsonrpc": "2.0", "result": null, "id": 1}`},
+		{NewSuccessResponseI(1, 0), `{"jsonrpc": "2.0", "result": 0, "id": 1}`},
+		{NewSuccessResponse(1, ""), `{"jsonrpc": "2.0", "result": "", "id": 1}`},
+		{NewResponseI(1, false, nil), `{"jsonrpc": "2.0", "result": false, "id": 1}`},
+		{NewResponseI[interface{}](1, nil, nil), `{"jsonrpc": "2.0", "result": null, "id": 1}`},
+		{NewResponseI(1, 0, &ErrorObj{Code: -32603, Message: "Internal error"}), `{"jsonrpc": "2.0", "error": {"code": -32603, "message": "Internal error"}, "id": 1}`},
+		{NewErrorResponseI(1, &ErrorObj{Code: -32603, Message: "Internal error"}), `{"jsonrpc": "2.0", "error": {"code": -32603, "message": "Internal error"}, "id": 1}`},
+	}
+	for _, test := range tests {
+		data, err := json.Marshal(test.response)
+		assert.Nil(err)
+		assert.JSONEq(test.expected, string(data))
+	}
+}
+
+func TestResultPresence(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		message string
+		result  string
+		kind    MessageKind
+	}{
+		{`{"jsonrpc": "2.0", "result": null, "id": 1}`, "null", SUCCESS_RESPONSE_KIND},
+		{`{"jsonrpc": "2.0", "result": 0, "id": 1}`, "0", SUCCESS_RESPONSE_KIND},
+		{`{"jsonrpc": "2.0", "id": 1}`, "", INVALID_KIND},
+	}
+	for _, test := range tests {
+		var rpcObj Object
+		assert.Nil(json.Unmarshal([]byte(test.message), &rpcObj))
+		msg := rpcObj.GetSingleMessage()
+		assert.Equal(test.result, string(msg.Result), test.message)
+		kind, _ := msg.GetKind()
+		assert.Equal(test.kind, kind, test.message)
+	}
+}
+
+func registerVoidMethods(reg RpcMethodRegistry) {
+	RegisterMethod(reg, "void", func(ctx context.Context, p []int) (interface{}, *Error) {
+		return nil, nil
+	})
+	RegisterMethod(reg, "zero", func(ctx context.Context, p []int) (int, *Error) {
+		return 0, nil
+	})
+}
+
+func assertVoidMethods(assert *assert.Assertions, c EndpointClient) {
+	response, err := Request[[]int, interface{}](context.Background(), c, "void", nil)
+	if assert.Nil(err) {
+		assert.True(response.IsSuccess())
+		assert.Nil(response.Result)
+	}
+	zero, err := Request[[]int, int](context.Background(), c, "zero", nil)
+	if assert.Nil(err) {
+		assert.True(zero.IsSuccess())
+		assert.Equal(0, zero.Result)
+	}
+}
+
+func TestStreamVoidMethods(t *testing.T) {
+	connA, connB := net.Pipe()
+	s := NewStreamEndpoint(context.Background(), NewPlainObjectStream(connA))
+	c := NewStreamEndpoint(context.Background(), NewPlainObjectStream(connB))
+	defer c.Close()
+	registerVoidMethods(s.GetMethods())
+	assertVoidMethods(assert.New(t), c)
+}
+
+func TestHttpVoidMethods(t *testing.T) {
+	mux := NewServerMux()
+	registerVoidMethods(mux.GetMethods())
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	assertVoidMethods(assert.New(t), NewHttpClientEndpoint(srv.URL, nil))
+}
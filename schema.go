@@ -0,0 +1,243 @@
+package jsonrpc2
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SchemaType is JSON Schema type keyword, marshalled as a single string if it has only one type
+type SchemaType []string
+
+func (t SchemaType) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+func (t *SchemaType) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*t = SchemaType{single}
+		return nil
+	}
+	return json.Unmarshal(data, (*[]string)(t))
+}
+
+func (t SchemaType) has(kind string) bool {
+	for _, k := range t {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// Schema is a subset of JSON Schema used to describe params and results of methods.
+type Schema struct {
+	Type                 SchemaType         `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+
+	// order of properties as declared in the struct
+	propertyOrder []string
+}
+
+var (
+	timeType          = reflect.TypeFor[time.Time]()
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// SchemaFor generates JSON Schema describing JSON encoding of the Go type.
+// Descriptions of struct fields are taken from the description tag, constraints
+// from the jsonschema tag, e.g. `jsonschema:"minimum=1,maxLength=10,enum=a|b"`.
+// Supported constraints are minimum, maximum, minLength, maxLength, pattern,
+// minItems, maxItems and enum. Values can not contain commas.
+func SchemaFor(t reflect.Type) *Schema {
+	return schemaFor(t, make(map[reflect.Type]bool))
+}
+
+func schemaFor(t reflect.Type, visiting map[reflect.Type]bool) *Schema {
+	if t == nil {
+		return &Schema{}
+	}
+	if t.Kind() == reflect.Pointer {
+		schema := schemaFor(t.Elem(), visiting)
+		if len(schema.Type) > 0 && !schema.Type.has("null") {
+			schema.Type = append(schema.Type, "null")
+		}
+		return schema
+	}
+	if t == timeType {
+		return &Schema{Type: SchemaType{"string"}, Format: "date-time"}
+	}
+	// custom encoding can not be described
+	if t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType) {
+		return &Schema{}
+	}
+	if t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType) {
+		return &Schema{Type: SchemaType{"string"}}
+	}
+	// recursive types are not expanded
+	if visiting[t] {
+		return &Schema{}
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: SchemaType{"boolean"}}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return &Schema{Type: SchemaType{"integer"}}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: SchemaType{"number"}}
+	case reflect.String:
+		return &Schema{Type: SchemaType{"string"}}
+	case reflect.Slice, reflect.Array:
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: SchemaType{"string"}, Format: "byte"}
+		}
+		schema := &Schema{Type: SchemaType{"array"}, Items: schemaFor(t.Elem(), visiting)}
+		if t.Kind() == reflect.Slice {
+			schema.Type = append(schema.Type, "null")
+		}
+		return schema
+	case reflect.Map:
+		return &Schema{Type: SchemaType{"object", "null"}, AdditionalProperties: schemaFor(t.Elem(), visiting)}
+	case reflect.Struct:
+		schema := &Schema{Type: SchemaType{"object"}, Properties: make(map[string]*Schema)}
+		addStructProperties(schema, t, visiting)
+		return schema
+	default:
+		return &Schema{}
+	}
+}
+
+// addStructProperties adds fields of the struct to the schema the same way encoding/json encodes them
+func addStructProperties(schema *Schema, t reflect.Type, visiting map[reflect.Type]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, omitEmpty, ok := jsonFieldName(field)
+		if !ok {
+			continue
+		}
+		if field.Anonymous && name == "" {
+			fieldType := field.Type
+			if fieldType.Kind() == reflect.Pointer {
+				fieldType = fieldType.Elem()
+			}
+			if fieldType.Kind() == reflect.Struct {
+				addStructProperties(schema, fieldType, visiting)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fieldSchema := schemaFor(field.Type, visiting)
+		if description := field.Tag.Get("description"); description != "" {
+			fieldSchema.Description = description
+		}
+		applySchemaConstraints(fieldSchema, field.Tag.Get("jsonschema"))
+		schema.Properties[name] = fieldSchema
+		schema.propertyOrder = append(schema.propertyOrder, name)
+		if !omitEmpty && field.Type.Kind() != reflect.Pointer {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
+
+// jsonFieldName returns name of the field from json tag, empty name if not set
+// and false if the field is not encoded
+func jsonFieldName(field reflect.StructField) (string, bool, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false, false
+	}
+	name, options, _ := strings.Cut(tag, ",")
+	omitEmpty := false
+	for _, option := range strings.Split(options, ",") {
+		if option == "omitempty" || option == "omitzero" {
+			omitEmpty = true
+		}
+	}
+	if !field.IsExported() && !field.Anonymous {
+		return "", false, false
+	}
+	return name, omitEmpty, true
+}
+
+// applySchemaConstraints applies constraints from jsonschema tag to the schema
+func applySchemaConstraints(schema *Schema, tag string) {
+	if tag == "" {
+		return
+	}
+	for _, constraint := range strings.Split(tag, ",") {
+		key, value, _ := strings.Cut(constraint, "=")
+		switch key {
+		case "minimum":
+			schema.Minimum = parseSchemaFloat(value)
+		case "maximum":
+			schema.Maximum = parseSchemaFloat(value)
+		case "minLength":
+			schema.MinLength = parseSchemaInt(value)
+		case "maxLength":
+			schema.MaxLength = parseSchemaInt(value)
+		case "minItems":
+			schema.MinItems = parseSchemaInt(value)
+		case "maxItems":
+			schema.MaxItems = parseSchemaInt(value)
+		case "pattern":
+			schema.Pattern = value
+		case "enum":
+			for _, item := range strings.Split(value, "|") {
+				var enumValue interface{} = item
+				if !schema.Type.has("string") {
+					// non string enums are written as json values, e.g. enum=1|2
+					if err := json.Unmarshal([]byte(item), &enumValue); err != nil {
+						enumValue = item
+					}
+				}
+				schema.Enum = append(schema.Enum, enumValue)
+			}
+		}
+	}
+}
+
+func parseSchemaFloat(value string) *float64 {
+	result, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil
+	}
+	return &result
+}
+
+func parseSchemaInt(value string) *int {
+	result, err := strconv.Atoi(value)
+	if err != nil {
+		return nil
+	}
+	return &result
+}
@@ -0,0 +1,184 @@
+package jsonrpc2
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// WithParamsValidation validates params against schema generated from the param type
+// before the handler is invoked. Invalid params are rejected with invalid params error
+// listing the violations in data.
+func WithParamsValidation() MethodOption {
+	return func(o *methodOptions) {
+		o.validateParams = true
+	}
+}
+
+// WithParamsSchema validates params against the schema before the handler is invoked.
+// The schema is also used to describe params in OpenRPC document.
+func WithParamsSchema(schema *Schema) MethodOption {
+	return func(o *methodOptions) {
+		o.validateParams = true
+		o.info.ParamsSchema = schema
+	}
+}
+
+func withParamsValidation(schema *Schema, handler RpcHandler) RpcHandler {
+	return func(ctx context.Context, rpcMsg *Message) interface{} {
+		if violations := schema.Validate(rpcMsg.Params); len(violations) > 0 {
+			return NewInvalidParamsWithData(violations).ToResponse(rpcMsg.Id)
+		}
+		return handler(ctx, rpcMsg)
+	}
+}
+
+// SchemaViolation describes value not matching the schema
+type SchemaViolation struct {
+	// Path of the value, e.g. $.transactions[0].hash
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// Validate validates JSON value against the schema. Missing value is validated as null.
+func (s *Schema) Validate(data json.RawMessage) []SchemaViolation {
+	if len(bytes.TrimSpace(data)) == 0 {
+		data = json.RawMessage("null")
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return []SchemaViolation{{Path: "$", Message: err.Error()}}
+	}
+	violations := []SchemaViolation{}
+	s.validate("$", value, &violations)
+	return violations
+}
+
+func (s *Schema) validate(path string, value interface{}, violations *[]SchemaViolation) {
+	if s == nil {
+		return
+	}
+	report := func(format string, args ...interface{}) {
+		*violations = append(*violations, SchemaViolation{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if len(s.Type) > 0 && !s.Type.has(jsonTypeOf(value)) && !(s.Type.has("number") && jsonTypeOf(value) == "integer") {
+		report("expected %s, got %s", strings.Join(s.Type, " or "), jsonTypeOf(value))
+		return
+	}
+	if len(s.Enum) > 0 && !enumContains(s.Enum, value) {
+		report("value is not one of allowed values")
+	}
+
+	switch v := value.(type) {
+	case json.Number:
+		number, _ := v.Float64()
+		if s.Minimum != nil && number < *s.Minimum {
+			report("must be greater than or equal to %v", *s.Minimum)
+		}
+		if s.Maximum != nil && number > *s.Maximum {
+			report("must be less than or equal to %v", *s.Maximum)
+		}
+	case string:
+		length := utf8.RuneCountInString(v)
+		if s.MinLength != nil && length < *s.MinLength {
+			report("length must be at least %d", *s.MinLength)
+		}
+		if s.MaxLength != nil && length > *s.MaxLength {
+			report("length must be at most %d", *s.MaxLength)
+		}
+		if s.Pattern != "" {
+			pattern, err := compileSchemaPattern(s.Pattern)
+			if err != nil {
+				report("invalid pattern %s: %s", s.Pattern, err.Error())
+			} else if !pattern.MatchString(v) {
+				report("must match pattern %s", s.Pattern)
+			}
+		}
+	case []interface{}:
+		if s.MinItems != nil && len(v) < *s.MinItems {
+			report("must have at least %d items", *s.MinItems)
+		}
+		if s.MaxItems != nil && len(v) > *s.MaxItems {
+			report("must have at most %d items", *s.MaxItems)
+		}
+		for i, item := range v {
+			s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item, violations)
+		}
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				*violations = append(*violations, SchemaViolation{Path: path + "." + name, Message: "is required"})
+			}
+		}
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if property, ok := s.Properties[name]; ok {
+				property.validate(path+"."+name, v[name], violations)
+			} else {
+				s.AdditionalProperties.validate(path+"."+name, v[name], violations)
+			}
+		}
+	}
+}
+
+func jsonTypeOf(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number:
+		if number, err := v.Float64(); err == nil && number == math.Trunc(number) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+func enumContains(enum []interface{}, value interface{}) bool {
+	if number, ok := value.(json.Number); ok {
+		value, _ = number.Float64()
+	}
+	encoded, _ := json.Marshal(value)
+	for _, allowed := range enum {
+		encodedAllowed, _ := json.Marshal(allowed)
+		if bytes.Equal(encoded, encodedAllowed) {
+			return true
+		}
+	}
+	return false
+}
+
+var schemaPatterns sync.Map
+
+func compileSchemaPattern(pattern string) (*regexp.Regexp, error) {
+	if compiled, ok := schemaPatterns.Load(pattern); ok {
+		return compiled.(*regexp.Regexp), nil
+	}
+	compiled, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	schemaPatterns.Store(pattern, compiled)
+	return compiled, nil
+}
@@ -0,0 +1,86 @@
+package jsonrpc2
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testTransferParams struct {
+	From   string   `json:"from" jsonschema:"pattern=^0x[0-9a-f]+$"`
+	Amount float64  `json:"amount" jsonschema:"minimum=0"`
+	Memo   string   `json:"memo,omitempty" jsonschema:"maxLength=4"`
+	Kind   string   `json:"kind,omitempty" jsonschema:"enum=fast|slow"`
+	Tags   []string `json:"tags,omitempty" jsonschema:"maxItems=1"`
+}
+
+func violationPaths(violations []SchemaViolation) []string {
+	paths := make([]string, 0, len(violations))
+	for _, violation := range violations {
+		paths = append(paths, violation.Path)
+	}
+	return paths
+}
+
+func TestSchemaValidate(t *testing.T) {
+	assert := assert.New(t)
+	schema := SchemaFor(reflect.TypeFor[testTransferParams]())
+
+	assert.Empty(schema.Validate(json.RawMessage(`{"from": "0xab", "amount": 1, "kind": "fast", "tags": ["a"]}`)))
+	assert.Equal([]string{"$"}, violationPaths(schema.Validate(nil)))
+	assert.Equal([]string{"$"}, violationPaths(schema.Validate(json.RawMessage(`[1, 2]`))))
+	assert.Equal([]string{"$.from", "$.amount"}, violationPaths(schema.Validate(json.RawMessage(`{}`))))
+	assert.Equal(
+		[]string{"$.amount", "$.from", "$.kind", "$.memo", "$.tags"},
+		violationPaths(schema.Validate(json.RawMessage(`{"from": "ab", "amount": -1, "memo": "too long", "kind": "other", "tags": ["a", "b"]}`))),
+	)
+	assert.Equal([]string{"$.tags[0]"}, violationPaths(schema.Validate(json.RawMessage(`{"from": "0xab", "amount": 1, "tags": [1]}`))))
+
+	integer := &Schema{Type: SchemaType{"integer"}}
+	assert.Empty(integer.Validate(json.RawMessage(`10`)))
+	assert.NotEmpty(integer.Validate(json.RawMessage(`10.5`)))
+	assert.Empty((&Schema{Type: SchemaType{"number"}}).Validate(json.RawMessage(`10`)))
+	assert.Empty((&Schema{Enum: []interface{}{1.0, "a"}}).Validate(json.RawMessage(`1`)))
+}
+
+func TestStreamParamsValidation(t *testing.T) {
+	assert := assert.New(t)
+	connA, connB := net.Pipe()
+	s := NewStreamEndpoint(context.Background(), NewPlainObjectStream(connA))
+	c := NewStreamEndpoint(context.Background(), NewPlainObjectStream(connB))
+	RegisterEndpointMethod(s, "transfer", func(ctx context.Context, p testTransferParams) (float64, *Error) {
+		return p.Amount, nil
+	}, WithParamsValidation())
+	minLength := 2
+	RegisterEndpointMethod(s, "echo", func(ctx context.Context, p string) (string, *Error) {
+		return p, nil
+	}, WithParamsSchema(&Schema{Type: SchemaType{"string"}, MinLength: &minLength}))
+
+	response, err := Request[testTransferParams, float64](context.Background(), c, "transfer", testTransferParams{From: "0x1", Amount: 10})
+	assert.Nil(err)
+	result, err := response.Unwrap()
+	assert.Nil(err)
+	assert.Equal(10.0, result)
+
+	response, err = Request[testTransferParams, float64](context.Background(), c, "transfer", testTransferParams{From: "1", Amount: -10})
+	assert.Nil(err)
+	if assert.NotNil(response.Error) {
+		assert.Equal(-32602, response.Error.Code)
+		var violations []SchemaViolation
+		assert.Nil(json.Unmarshal(*response.Error.Data, &violations))
+		assert.Equal([]string{"$.amount", "$.from"}, violationPaths(violations))
+	}
+
+	echo, err := Request[[]string, string](context.Background(), c, "echo", []string{"a"})
+	assert.Nil(err)
+	if assert.NotNil(echo.Error) {
+		assert.Equal(-32602, echo.Error.Code)
+	}
+	echo, err = Request[[]string, string](context.Background(), c, "echo", []string{"ab"})
+	assert.Nil(err)
+	assert.Nil(echo.Error)
+}
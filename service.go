@@ -0,0 +1,183 @@
+package jsonrpc2
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+var (
+	contextType = reflect.TypeFor[context.Context]()
+	rpcErrType  = reflect.TypeFor[*Error]()
+	errType     = reflect.TypeFor[error]()
+)
+
+// CamelCaseNaming converts Go method name to camelCase, e.g. GetBlock -> getBlock.
+func CamelCaseNaming(name string) string {
+	runes := []rune(name)
+	for i := range runes {
+		// lower leading upper case run except the last letter which starts the next word, e.g. HTTPStatus -> httpStatus
+		if !unicode.IsUpper(runes[i]) || (i > 0 && i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+			break
+		}
+		runes[i] = unicode.ToLower(runes[i])
+	}
+	return string(runes)
+}
+
+// SnakeCaseNaming converts Go method name to snake_case, e.g. GetBlockByID -> get_block_by_id.
+func SnakeCaseNaming(name string) string {
+	runes := []rune(name)
+	var builder strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				builder.WriteRune('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		builder.WriteRune(r)
+	}
+	return builder.String()
+}
+
+type serviceOptions struct {
+	naming        func(name string) string
+	names         map[string]string
+	methodOptions []MethodOption
+}
+
+// ServiceOption configures RegisterService
+type ServiceOption func(*serviceOptions)
+
+// WithNaming sets function converting Go method names to rpc method names. CamelCaseNaming is used by default.
+func WithNaming(naming func(name string) string) ServiceOption {
+	return func(o *serviceOptions) {
+		o.naming = naming
+	}
+}
+
+// WithMethodNames sets explicit rpc method names by Go method name. Method named "-" is not registered.
+func WithMethodNames(names map[string]string) ServiceOption {
+	return func(o *serviceOptions) {
+		for method, name := range names {
+			o.names[method] = name
+		}
+	}
+}
+
+// WithServiceMethodOptions applies method options to every method of the service.
+func WithServiceMethodOptions(opts ...MethodOption) ServiceOption {
+	return func(o *serviceOptions) {
+		o.methodOptions = append(o.methodOptions, opts...)
+	}
+}
+
+// RegisterService registers exported methods of svc as rpc methods named prefix.methodName
+// (or just methodName if prefix is empty). Methods have to match
+// func(ctx context.Context, p TParam) (TResult, *Error) or func(ctx context.Context, p TParam) (TResult, error).
+// If any exported method does not match or its rpc name is already used, nothing is registered and error is returned.
+func RegisterService(reg RpcMethodRegistry, prefix string, svc interface{}, opts ...ServiceOption) error {
+	options := &serviceOptions{
+		naming: CamelCaseNaming,
+		names:  make(map[string]string),
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	value := reflect.ValueOf(svc)
+	if !value.IsValid() {
+		return errors.New("jsonrpc2: service is nil")
+	}
+	methodOptions := newMethodOptions(options.methodOptions)
+	handlers := make(map[string]reflect.Value, value.NumMethod())
+	goNames := make(map[string]string, value.NumMethod())
+	var errs []error
+	for i := 0; i < value.NumMethod(); i++ {
+		goName := value.Type().Method(i).Name
+		name, ok := options.names[goName]
+		if !ok {
+			name = options.naming(goName)
+		}
+		if name == "-" {
+			continue
+		}
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+		if err := validateServiceMethod(value.Method(i), methodOptions); err != nil {
+			errs = append(errs, fmt.Errorf("jsonrpc2: method %s: %w", goName, err))
+			continue
+		}
+		if other, ok := goNames[name]; ok {
+			errs = append(errs, fmt.Errorf("jsonrpc2: method %s: rpc name %s already used by method %s", goName, name, other))
+			continue
+		}
+		if _, ok := reg[name]; ok {
+			errs = append(errs, fmt.Errorf("jsonrpc2: method %s: rpc method %s already registered", goName, name))
+			continue
+		}
+		handlers[name] = value.Method(i)
+		goNames[name] = goName
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	for name, method := range handlers {
+		methodType := method.Type()
+		if err := registerHandler(reg, name, methodType.In(1), methodType.Out(0), newServiceMethodHandler(method, methodOptions.strictParams), methodOptions); err != nil {
+			return fmt.Errorf("jsonrpc2: method %s: %w", goNames[name], err)
+		}
+	}
+	return nil
+}
+
+// RegisterEndpointService registers service on the server endpoint, see RegisterService
+func RegisterEndpointService(c EndpointServer, prefix string, svc interface{}, opts ...ServiceOption) error {
+	if c == nil {
+		return ErrInvalidEndpoint
+	}
+	return RegisterService(c.GetMethods(), prefix, svc, opts...)
+}
+
+func validateServiceMethod(method reflect.Value, options *methodOptions) error {
+	methodType := method.Type()
+	if methodType.NumIn() != 2 || methodType.In(0) != contextType {
+		return errors.New("expected arguments (context.Context, TParam)")
+	}
+	if methodType.NumOut() != 2 || (methodType.Out(1) != rpcErrType && methodType.Out(1) != errType) {
+		return errors.New("expected results (TResult, *Error) or (TResult, error)")
+	}
+	_, err := options.getPositionalParams(methodType.In(1))
+	return err
+}
+
+func newServiceMethodHandler(method reflect.Value, strictParams bool) RpcHandler {
+	paramType := method.Type().In(1)
+
+	return func(ctx context.Context, rpcMsg *Message) interface{} {
+		params := reflect.New(paramType)
+		if err := decodeParams(rpcMsg.Params, params.Interface(), strictParams); err != nil {
+			return err.ToResponse(rpcMsg.Id)
+		}
+		out := method.Call([]reflect.Value{reflect.ValueOf(ctx), params.Elem()})
+		if !out[1].IsNil() {
+			return toRpcError(out[1].Interface().(error)).ToResponse(rpcMsg.Id)
+		}
+		return NewSuccessResponseI(rpcMsg.Id, out[0].Interface())
+	}
+}
+
+// toRpcError converts error returned by a handler to rpc error
+func toRpcError(err error) *Error {
+	var rpcErr *Error
+	if errors.As(err, &rpcErr) {
+		return rpcErr
+	}
+	return NewInternalErrorWithData(err.Error())
+}
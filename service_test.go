@@ -0,0 +1,138 @@
+package jsonrpc2
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testGreeter struct {
+	greeting string
+}
+
+func (g *testGreeter) SayHello(ctx context.Context, name string) (string, *Error) {
+	return g.greeting + " " + name, nil
+}
+
+func (g *testGreeter) GetHTTPStatus(ctx context.Context, code int) (int, error) {
+	if code < 0 {
+		return 0, errors.New("negative code")
+	}
+	if code == 0 {
+		return 0, NewInvalidParams()
+	}
+	return code, nil
+}
+
+type testInvalidService struct{}
+
+func (testInvalidService) Valid(ctx context.Context, p string) (string, *Error) {
+	return p, nil
+}
+
+func (testInvalidService) NoContext(p string) (string, *Error) {
+	return p, nil
+}
+
+func (testInvalidService) NoError(ctx context.Context, p string) string {
+	return p
+}
+
+func TestMethodNaming(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal("getBlock", CamelCaseNaming("GetBlock"))
+	assert.Equal("httpStatus", CamelCaseNaming("HTTPStatus"))
+	assert.Equal("id", CamelCaseNaming("ID"))
+	assert.Equal("get_block_by_id", SnakeCaseNaming("GetBlockByID"))
+	assert.Equal("http_status", SnakeCaseNaming("HTTPStatus"))
+}
+
+func TestRegisterService(t *testing.T) {
+	assert := assert.New(t)
+	reg := NewMethodRegistry()
+	assert.Nil(RegisterService(reg, "greeter", &testGreeter{greeting: "Hello"}))
+	assert.Contains(reg, "greeter.sayHello")
+	assert.Contains(reg, "greeter.getHTTPStatus")
+
+	reg = NewMethodRegistry()
+	assert.Nil(RegisterService(reg, "", &testGreeter{greeting: "Hello"}, WithNaming(SnakeCaseNaming), WithMethodNames(map[string]string{
+		"GetHTTPStatus": "-",
+	})))
+	assert.Len(reg, 1)
+	assert.Contains(reg, "say_hello")
+}
+
+func TestRegisterServiceInvalidSignature(t *testing.T) {
+	assert := assert.New(t)
+	reg := NewMethodRegistry()
+	err := RegisterService(reg, "invalid", testInvalidService{})
+	assert.ErrorContains(err, "NoContext")
+	assert.ErrorContains(err, "NoError")
+	assert.NotContains(err.Error(), "Valid:")
+	assert.Len(reg, 0)
+
+	assert.Nil(RegisterService(reg, "invalid", testInvalidService{}, WithMethodNames(map[string]string{
+		"NoContext": "-",
+		"NoError":   "-",
+	})))
+	assert.Len(reg, 1)
+	assert.NotNil(RegisterService(reg, "", nil))
+}
+
+func TestRegisterServiceDuplicateNames(t *testing.T) {
+	assert := assert.New(t)
+	reg := NewMethodRegistry()
+	err := RegisterService(reg, "greeter", &testGreeter{greeting: "Hello"}, WithMethodNames(map[string]string{
+		"GetHTTPStatus": "sayHello",
+	}))
+	assert.ErrorContains(err, "greeter.sayHello")
+	assert.Len(reg, 0)
+
+	assert.Nil(RegisterService(reg, "greeter", &testGreeter{greeting: "Hello"}))
+	err = RegisterService(reg, "greeter", &testGreeter{greeting: "Hi"}, WithMethodNames(map[string]string{
+		"GetHTTPStatus": "-",
+	}))
+	assert.ErrorContains(err, "greeter.sayHello already registered")
+	assert.Len(reg, 2)
+	// first registration is kept
+	response, err := json.Marshal(processRpcRequest(context.Background(), reg, &Message{Id: &ID{"1"}, Method: "greeter.sayHello", Params: []byte(`["World"]`)}, nil, &serverConfig{}, nil))
+	assert.Nil(err)
+	assert.JSONEq(`{"jsonrpc": "2.0", "id": "1", "result": "Hello World"}`, string(response))
+}
+
+func TestStreamRegisterService(t *testing.T) {
+	assert := assert.New(t)
+	connA, connB := net.Pipe()
+	s := NewStreamEndpoint(context.Background(), NewPlainObjectStream(connA))
+	c := NewStreamEndpoint(context.Background(), NewPlainObjectStream(connB))
+	assert.Nil(RegisterEndpointService(s, "greeter", &testGreeter{greeting: "Hello"}))
+
+	response, err := Request[[]string, string](context.Background(), c, "greeter.sayHello", []string{"World"})
+	assert.Nil(err)
+	result, err := response.Unwrap()
+	assert.Nil(err)
+	assert.Equal("Hello World", result)
+
+	status, err := Request[[]int, int](context.Background(), c, "greeter.getHTTPStatus", []int{200})
+	assert.Nil(err)
+	code, err := status.Unwrap()
+	assert.Nil(err)
+	assert.Equal(200, code)
+
+	status, err = Request[[]int, int](context.Background(), c, "greeter.getHTTPStatus", []int{-1})
+	assert.Nil(err)
+	assert.Equal(-32603, status.Error.Code)
+	assert.Equal("\"negative code\"", string(*status.Error.Data))
+
+	status, err = Request[[]int, int](context.Background(), c, "greeter.getHTTPStatus", []int{0})
+	assert.Nil(err)
+	assert.Equal(-32602, status.Error.Code)
+
+	status, err = Request[[]string, int](context.Background(), c, "greeter.getHTTPStatus", []string{"not a number"})
+	assert.Nil(err)
+	assert.Equal(-32602, status.Error.Code)
+}
@@ -0,0 +1,84 @@
+package jsonrpc2
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamShutdown(t *testing.T) {
+	assert := assert.New(t)
+	connA, connB := net.Pipe()
+	s := NewStreamEndpoint(context.Background(), NewPlainObjectStream(connA))
+	c := NewStreamEndpoint(context.Background(), NewPlainObjectStream(connB))
+	release := make(chan struct{})
+	RegisterEndpointMethod(s, "wait", func(ctx context.Context, name string) (string, *Error) {
+		<-release
+		return "Hello " + name, nil
+	})
+	RegisterEndpointMethod(c, "hello", func(ctx context.Context, name string) (string, *Error) {
+		return "Hello " + name, nil
+	})
+
+	call := Go[[]string, string](context.Background(), c, "wait", []string{"World"})
+	assert.Eventually(func() bool {
+		return s.ConcurrencyStats().ActiveHandlers == 1
+	}, time.Second, 10*time.Millisecond)
+
+	shutdown := make(chan error, 1)
+	go func() {
+		shutdown <- s.Shutdown(context.Background())
+	}()
+	assert.Eventually(func() bool {
+		response, err := Request[[]string, string](context.Background(), c, "wait", []string{"World"})
+		return err == nil && response.Error != nil && response.Error.Code == ShuttingDownErrorCode
+	}, time.Second, 10*time.Millisecond)
+
+	// requests sent by the endpoint being shut down are still resolved
+	response, err := Request[[]string, string](context.Background(), s, "hello", []string{"World"})
+	assert.Nil(err)
+	assert.Equal("Hello World", response.Result)
+
+	select {
+	case <-shutdown:
+		t.Fatal("shutdown finished before in-flight request")
+	default:
+	}
+	close(release)
+	response, err = call.Wait(context.Background())
+	assert.Nil(err)
+	assert.Equal("Hello World", response.Result)
+	assert.Nil(<-shutdown)
+	s.Wait()
+	assert.True(s.IsClosed())
+	c.Wait()
+	assert.True(c.IsClosed())
+}
+
+func TestStreamShutdownTimeout(t *testing.T) {
+	assert := assert.New(t)
+	connA, connB := net.Pipe()
+	s := NewStreamEndpoint(context.Background(), NewPlainObjectStream(connA))
+	c := NewStreamEndpoint(context.Background(), NewPlainObjectStream(connB))
+	release := make(chan struct{})
+	defer close(release)
+	RegisterEndpointMethod(s, "wait", func(ctx context.Context, name string) (string, *Error) {
+		<-release
+		return "Hello " + name, nil
+	})
+
+	call := Go[[]string, string](context.Background(), c, "wait", []string{"World"})
+	assert.Eventually(func() bool {
+		return s.ConcurrencyStats().ActiveHandlers == 1
+	}, time.Second, 10*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(s.Shutdown(ctx), context.DeadlineExceeded)
+	s.Wait()
+	_, err := call.Wait(context.Background())
+	assert.ErrorIs(err, ErrStreamClosed)
+}
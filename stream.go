@@ -2,10 +2,12 @@ package jsonrpc2
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"io"
 	"log/slog"
 	"sync"
+	"sync/atomic"
 )
 
 var (
@@ -13,37 +15,87 @@ var (
 	ErrStreamClosed    = errors.New("stream closed")
 )
 
+const (
+	DefaultCancelRequestMethod = "$/cancelRequest"
+	// ShuttingDownErrorCode is code of server error returned to requests received during shutdown
+	ShuttingDownErrorCode = -32002
+)
+
 // StreamEndpoint is a endpoint that implements both client and server side of jsonrpc over a stream.
 // Usually used over tcp or stdio streams.
 type StreamEndpoint struct {
+	clientConfig
+	serverConfig
+	protocolConfig
+
 	stream ObjectStream
 
 	pendingMutex sync.Mutex
 	closed       bool
-	pending      map[interface{}]chan message
+	pending      map[string]chan Message
 
 	writeMutex sync.Mutex
 
+	handlersMutex sync.Mutex
+	handlers      int
+	handlersIdle  chan struct{}
+	shuttingDown  bool
+
+	scheduler *messageScheduler
+
+	// contexts of in-flight requests by request id, tracked if cancellation is enabled
+	cancellableMutex sync.Mutex
+	cancellable      map[string]context.CancelFunc
+
 	closeNotify chan struct{}
+	closeCause  error
+	readerDone  chan struct{}
+
+	keepalive atomic.Pointer[keepalive]
 
 	logger *slog.Logger
 	// Set by ConnOpt funcs.
 	methodRegistry RpcMethodRegistry
+	middlewares    []RpcMiddleware
 }
 
-func NewStreamEndpoint(ctx context.Context, stream ObjectStream) *StreamEndpoint {
-	c := &StreamEndpoint{
+// ConnOpt configures StreamEndpoint before it starts reading messages, e.g. sets
+// protocol options, registers methods and middlewares
+type ConnOpt func(c *StreamEndpoint)
+
+// NewStreamEndpoint creates endpoint configured by opts and starts reading messages from the stream.
+// Options which have to be set before the first message is received are meant to be set in opts.
+func NewStreamEndpoint(ctx context.Context, stream ObjectStream, opts ...ConnOpt) *StreamEndpoint {
+	c := newStreamEndpoint(stream)
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.start(ctx)
+	return c
+}
+
+// newStreamEndpoint creates endpoint without starting the reader
+// so it can be configured before the first message arrives
+func newStreamEndpoint(stream ObjectStream) *StreamEndpoint {
+	return &StreamEndpoint{
 		stream:         stream,
-		pending:        make(map[interface{}]chan message, 1),
+		pending:        make(map[string]chan Message, 1),
 		closeNotify:    make(chan struct{}),
+		readerDone:     make(chan struct{}),
 		methodRegistry: NewMethodRegistry(),
 		logger:         slog.Default(),
+		scheduler:      newMessageScheduler(),
 	}
-	go c.readMessages(ctx)
-	return c
 }
 
-// returns a channel that will be closed when the connection is closed
+func (c *StreamEndpoint) start(ctx context.Context) {
+	go func() {
+		defer close(c.readerDone)
+		c.readMessages(ctx)
+	}()
+}
+
+// returns a channel that will be closed when the connection is closed, use CloseCause to get the reason
 func (c *StreamEndpoint) GetOnCloseListener() <-chan struct{} {
 	return c.closeNotify
 }
@@ -59,6 +111,7 @@ func (c *StreamEndpoint) close(cause error) error {
 	}
 
 	c.closed = true
+	c.closeCause = cause
 	for _, pendingChannel := range c.pending {
 		close(pendingChannel)
 	}
@@ -75,6 +128,28 @@ func (c *StreamEndpoint) Close() error {
 	return c.close(nil)
 }
 
+// Shutdown gracefully closes the endpoint. New requests are rejected with shutting down error,
+// in-flight messages are processed and their responses written, then the endpoint is closed.
+// Responses to requests sent through the endpoint are still received until it is closed.
+// If context expires first, the endpoint is closed immediately and context error is returned.
+func (c *StreamEndpoint) Shutdown(ctx context.Context) error {
+	c.handlersMutex.Lock()
+	c.shuttingDown = true
+	c.handlersMutex.Unlock()
+
+	err := c.drain(ctx)
+	if closeErr := c.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// Wait blocks until the reader of the endpoint exits, which happens when the endpoint is closed
+// or reading from the stream fails.
+func (c *StreamEndpoint) Wait() {
+	<-c.readerDone
+}
+
 func (c *StreamEndpoint) GetMethods() RpcMethodRegistry {
 	return c.methodRegistry
 }
@@ -95,7 +170,93 @@ func (c *StreamEndpoint) UseLogger(logger *slog.Logger) {
 	c.logger = logger
 }
 
+// UseMiddleware wraps all methods served by the endpoint in middlewares,
+// including methods registered later. First middleware is the outermost one.
+// Should be set in ConnOpt passed to NewStreamEndpoint.
+func (c *StreamEndpoint) UseMiddleware(middlewares ...RpcMiddleware) {
+	c.middlewares = append(c.middlewares, middlewares...)
+}
+
+// UseRequestCancellation enables cancellation protocol. When context of a request sent
+// through the endpoint is cancelled, notification with the request id is sent to the peer.
+// When such notification is received, context of the matching in-flight request is cancelled.
+// If method is empty DefaultCancelRequestMethod is used.
+func (c *StreamEndpoint) UseRequestCancellation(method string) {
+	if method == "" {
+		method = DefaultCancelRequestMethod
+	}
+	c.cancellableMutex.Lock()
+	defer c.cancellableMutex.Unlock()
+	c.cancelMethod = method
+	if c.cancellable == nil {
+		c.cancellable = make(map[string]context.CancelFunc)
+	}
+}
+
+func (c *StreamEndpoint) isCancelRequest(rpcMsg *Message) bool {
+	c.cancellableMutex.Lock()
+	defer c.cancellableMutex.Unlock()
+	return c.cancellable != nil && rpcMsg.Method == c.cancelMethod && rpcMsg.Id == nil
+}
+
+func (c *StreamEndpoint) cancelRequest(rpcMsg *Message) {
+	var params struct {
+		Id *ID `json:"id"`
+	}
+	if err := json.Unmarshal(rpcMsg.Params, &params); err != nil {
+		c.logger.Debug("jsonrpc2: ignoring invalid cancel request", "error", err)
+		return
+	}
+	c.cancellableMutex.Lock()
+	defer c.cancellableMutex.Unlock()
+	if cancel, ok := c.cancellable[idKey(params.Id)]; ok {
+		c.logger.Debug("jsonrpc2: cancelling request", "request_id", params.Id)
+		cancel()
+	}
+}
+
+// cancellableContexts creates contexts of requests which are cancelled when cancel request
+// for them is received. Contexts are registered before the messages are dispatched, so cancel
+// request received while the request is queued is not lost. Returns nil if cancellation is disabled.
+func (c *StreamEndpoint) cancellableContexts(ctx context.Context, messages []Message) []context.Context {
+	c.cancellableMutex.Lock()
+	defer c.cancellableMutex.Unlock()
+	if c.cancellable == nil {
+		return nil
+	}
+	contexts := make([]context.Context, len(messages))
+	for i := range messages {
+		if kind, _ := messages[i].getKind(c.isStrict()); kind != REQUEST_KIND {
+			continue
+		}
+		requestCtx, cancel := context.WithCancel(ctx)
+		c.cancellable[idKey(messages[i].Id)] = cancel
+		contexts[i] = requestCtx
+	}
+	return contexts
+}
+
+// releaseCancellable stops tracking contexts of the requests once they are processed or rejected
+func (c *StreamEndpoint) releaseCancellable(messages []Message, contexts []context.Context) {
+	if contexts == nil {
+		return
+	}
+	c.cancellableMutex.Lock()
+	defer c.cancellableMutex.Unlock()
+	for i := range messages {
+		if contexts[i] == nil {
+			continue
+		}
+		key := idKey(messages[i].Id)
+		if cancel, ok := c.cancellable[key]; ok {
+			delete(c.cancellable, key)
+			cancel()
+		}
+	}
+}
+
 func (c *StreamEndpoint) readMessages(ctx context.Context) {
+	handlerCtx := context.WithValue(ctx, endpointContextKey, c)
 	var err error
 	for err == nil {
 		if ctx.Err() != nil {
@@ -106,72 +267,209 @@ func (c *StreamEndpoint) readMessages(ctx context.Context) {
 		err = c.stream.ReadObject(&rpcObj)
 		if err != nil {
 			c.logger.Debug("jsonrpc2: error reading message", "error", err)
+			var syntaxErr *json.SyntaxError
+			if c.isStrict() && errors.As(err, &syntaxErr) {
+				c.writeResults([]interface{}{NewParseErrorWithData(err.Error()).ToResponse(nil)}, false)
+			}
 			break
 		}
 		c.logger.Debug("jsonrpc2: received message", "message", rpcObj)
-		go func() {
-			messages := rpcObj.GetMessages()
-			results := make([]interface{}, 0, len(messages))
-			for _, rpcMsg := range messages {
-				kind, err := rpcMsg.GetKind()
-				switch kind {
-				case REQUEST_KIND:
-					results = append(results, ProcessRpcRequest(ctx, c.methodRegistry, &rpcMsg))
-				case NOTIFICATION_KIND:
-					_ = ProcessRpcRequest(ctx, c.methodRegistry, &rpcMsg)
-				case SUCCESS_RESPONSE_KIND:
-					fallthrough
-				case ERROR_RESPONSE_KIND:
-					pendingChannel, ok := c.pending[rpcMsg.Id]
-					if !ok {
-						c.logger.Debug("jsonrpc2: ignoring response with no corresponding request", "response_id", rpcMsg.Id)
-						continue
-					}
-					pendingChannel <- rpcMsg
-				default:
-					c.logger.Debug("jsonrpc2: ignoring invalid message", "kind", kind, "error", err)
+		if c.isLegacy() {
+			rpcObj.upgradeLegacy()
+		}
+		if rpcObj.IsEmptyBatch() {
+			if c.isStrict() {
+				c.writeResults([]interface{}{NewInvalidRequestWithData(ErrInternalEmptyBatch.Error()).ToResponse(nil)}, false)
+			}
+			continue
+		}
+		// responses are resolved right away so they can not outlive the stream
+		messages := make([]Message, 0, len(rpcObj.GetMessages()))
+		keepalive := c.keepalive.Load()
+		for _, rpcMsg := range rpcObj.GetMessages() {
+			if !keepalive.isPing(&rpcMsg) {
+				keepalive.touch()
+			} else if rpcMsg.Method != "" {
+				// answered outside of the reader, so peers answering pings of each other
+				// do not block on writes while neither of them reads
+				go c.answerPing(rpcMsg)
+				continue
+			}
+			kind, _ := rpcMsg.getKind(c.isStrict())
+			switch kind {
+			case SUCCESS_RESPONSE_KIND, ERROR_RESPONSE_KIND:
+				c.resolvePendingRequest(rpcMsg)
+			case NOTIFICATION_KIND:
+				if c.isCancelRequest(&rpcMsg) {
+					c.cancelRequest(&rpcMsg)
 					continue
 				}
+				messages = append(messages, rpcMsg)
+			default:
+				messages = append(messages, rpcMsg)
 			}
+		}
+		if len(messages) == 0 {
+			continue
+		}
 
-			if len(results) == 0 {
-				return
-			}
+		isBatch := rpcObj.IsBatch()
+		if !c.beginHandler() {
+			c.rejectMessages(messages, isBatch, NewServerErrorWithData(ShuttingDownErrorCode, "shutting down"))
+			continue
+		}
+		contexts := c.cancellableContexts(handlerCtx, messages)
+		if !c.dispatch(messages, func() { c.handleMessages(handlerCtx, messages, contexts, isBatch) }) {
+			c.endHandler()
+			c.releaseCancellable(messages, contexts)
+			c.rejectMessages(messages, isBatch, c.overloadedError())
+		}
+	}
+	c.close(err)
+}
 
-			c.writeMutex.Lock()
-			defer c.writeMutex.Unlock()
-			if rpcObj.IsBatch() {
-				c.logger.Debug("jsonrpc2: sending batch response", "response", results)
-				c.stream.WriteObject(results)
-				return
+// handleMessages processes requests and notifications received in one object and writes responses.
+// Contexts are cancellable contexts of requests, nil if cancellation is disabled.
+func (c *StreamEndpoint) handleMessages(ctx context.Context, messages []Message, contexts []context.Context, isBatch bool) {
+	defer c.releaseCancellable(messages, contexts)
+	// handlers which outlived their deadline keep the handler slot until they return
+	outliving := &outlivingHandlers{}
+	defer outliving.Wait()
+	results := make([]interface{}, 0, len(messages))
+	for i, rpcMsg := range messages {
+		kind, err := rpcMsg.getKind(c.isStrict())
+		switch kind {
+		case REQUEST_KIND:
+			requestCtx := ctx
+			if contexts != nil && contexts[i] != nil {
+				requestCtx = contexts[i]
 			}
-			c.logger.Debug("jsonrpc2: sending response", "response", results[0])
-			c.stream.WriteObject(results[0])
-		}()
+			results = append(results, processRpcRequest(withOutlivingHandlers(requestCtx, outliving), c.methodRegistry, &rpcMsg, c.middlewares, &c.serverConfig, c.logger))
+		case NOTIFICATION_KIND:
+			_ = processRpcRequest(withOutlivingHandlers(ctx, outliving), c.methodRegistry, &rpcMsg, c.middlewares, &c.serverConfig, c.logger)
+		default:
+			if c.isStrict() {
+				c.logger.Debug("jsonrpc2: invalid message", "error", err)
+				if response := invalidMessageResponse(&rpcMsg, err); response != nil {
+					results = append(results, response)
+				}
+				continue
+			}
+			c.logger.Debug("jsonrpc2: ignoring invalid message", "kind", kind, "error", err)
+		}
+	}
+	c.writeResults(results, isBatch)
+}
+
+func (c *StreamEndpoint) writeResults(results []interface{}, isBatch bool) {
+	if len(results) == 0 {
+		return
+	}
+	c.keepalive.Load().touch()
+
+	if isBatch {
+		c.logger.Debug("jsonrpc2: sending batch response", "response", results)
+		c.writeObject(results)
+		return
+	}
+	c.logger.Debug("jsonrpc2: sending response", "response", results[0])
+	c.writeObject(results[0])
+}
+
+func (c *StreamEndpoint) resolvePendingRequest(rpcMsg Message) {
+	c.pendingMutex.Lock()
+	defer c.pendingMutex.Unlock()
+	if c.closed {
+		return
+	}
+	pendingChannel, ok := c.pending[idKey(rpcMsg.Id)]
+	if !ok {
+		c.logger.Debug("jsonrpc2: ignoring response with no corresponding request", "response_id", rpcMsg.Id)
+		return
+	}
+	select {
+	case pendingChannel <- rpcMsg:
+	default:
+		c.logger.Debug("jsonrpc2: ignoring duplicate response", "response_id", rpcMsg.Id)
+	}
+}
+
+// beginHandler registers in-flight message, returns false if the endpoint is shutting down
+func (c *StreamEndpoint) beginHandler() bool {
+	c.handlersMutex.Lock()
+	defer c.handlersMutex.Unlock()
+	if c.shuttingDown {
+		return false
+	}
+	c.handlers++
+	return true
+}
+
+func (c *StreamEndpoint) endHandler() {
+	c.handlersMutex.Lock()
+	defer c.handlersMutex.Unlock()
+	c.handlers--
+	if c.handlers == 0 && c.handlersIdle != nil {
+		close(c.handlersIdle)
+		c.handlersIdle = nil
+	}
+}
+
+// drain waits until all in-flight messages are processed and their responses written
+func (c *StreamEndpoint) drain(ctx context.Context) error {
+	c.handlersMutex.Lock()
+	if c.handlers == 0 {
+		c.handlersMutex.Unlock()
+		return nil
+	}
+	if c.handlersIdle == nil {
+		c.handlersIdle = make(chan struct{})
+	}
+	idle := c.handlersIdle
+	c.handlersMutex.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
-	c.close(err)
 }
 
 func (c *StreamEndpoint) WriteObject(obj interface{}) error {
+	c.keepalive.Load().touch()
+	return c.writeObject(obj)
+}
+
+func (c *StreamEndpoint) writeObject(obj interface{}) error {
+	if c.isLegacy() {
+		data, err := c.encodeObject(obj)
+		if err != nil {
+			return err
+		}
+		obj = data
+	}
 	c.writeMutex.Lock()
 	defer c.writeMutex.Unlock()
 	return c.stream.WriteObject(obj)
 }
 
-func (c *StreamEndpoint) RegisterPendingRequest(requestId interface{}) <-chan message {
-	ch := make(chan message, 1)
+func (c *StreamEndpoint) RegisterPendingRequest(requestId interface{}) <-chan Message {
+	ch := make(chan Message, 1)
 	c.pendingMutex.Lock()
-	c.pending[requestId] = ch
+	c.pending[idKey(requestId)] = ch
 	c.pendingMutex.Unlock()
 	return ch
 }
 
 func (c *StreamEndpoint) UnregisterPendingRequest(requestId interface{}) {
 	c.pendingMutex.Lock()
-	delete(c.pending, requestId)
+	delete(c.pending, idKey(requestId))
 	c.pendingMutex.Unlock()
 }
 
 func (c *StreamEndpoint) IsClosed() bool {
+	c.pendingMutex.Lock()
+	defer c.pendingMutex.Unlock()
 	return c.closed
 }
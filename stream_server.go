@@ -0,0 +1,221 @@
+package jsonrpc2
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"sync"
+	"time"
+)
+
+var (
+	ErrServerClosed = errors.New("server closed")
+)
+
+// StreamServer accepts connections on a listener and serves jsonrpc over each of them
+// with its own StreamEndpoint. All endpoints share methods registered on the server.
+type StreamServer struct {
+	listener net.Listener
+	codec    ObjectCodec
+
+	methods RpcMethodRegistry
+
+	mutex        sync.Mutex
+	onConnect    func(endpoint *StreamEndpoint)
+	onDisconnect func(endpoint *StreamEndpoint)
+	closed       bool
+	connections  map[*StreamEndpoint]struct{}
+	served       sync.WaitGroup
+
+	logger *slog.Logger
+}
+
+// NewStreamServer creates server accepting connections on the listener.
+// Codec specifies how objects are framed on the connection, if nil plain
+// JSON-RPC 2.0 objects without a header are used.
+func NewStreamServer(listener net.Listener, codec ObjectCodec) *StreamServer {
+	return &StreamServer{
+		listener:    listener,
+		codec:       codec,
+		methods:     NewMethodRegistry(),
+		connections: make(map[*StreamEndpoint]struct{}),
+		logger:      slog.Default(),
+	}
+}
+
+func (s *StreamServer) GetMethods() RpcMethodRegistry {
+	return s.methods
+}
+
+func (s *StreamServer) UseLogger(logger *slog.Logger) {
+	if logger == nil {
+		s.logger.Debug("ignored nil logger")
+		return
+	}
+	s.logger = logger
+}
+
+// OnConnect sets callback invoked for every accepted connection before the endpoint
+// starts reading messages. Every connection is set up in its own goroutine, so slow
+// callback does not delay other connections.
+func (s *StreamServer) OnConnect(onConnect func(endpoint *StreamEndpoint)) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.onConnect = onConnect
+}
+
+// OnDisconnect sets callback invoked after connection endpoint is closed.
+func (s *StreamServer) OnDisconnect(onDisconnect func(endpoint *StreamEndpoint)) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.onDisconnect = onDisconnect
+}
+
+func (s *StreamServer) getHooks() (func(endpoint *StreamEndpoint), func(endpoint *StreamEndpoint)) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.onConnect, s.onDisconnect
+}
+
+// Connections returns endpoints of all live connections.
+func (s *StreamServer) Connections() []*StreamEndpoint {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	result := make([]*StreamEndpoint, 0, len(s.connections))
+	for endpoint := range s.connections {
+		result = append(result, endpoint)
+	}
+	return result
+}
+
+func (s *StreamServer) newStream(conn net.Conn) ObjectStream {
+	if s.codec == nil {
+		return NewPlainObjectStream(conn)
+	}
+	return NewBufferedStream(conn, s.codec)
+}
+
+// Serve accepts connections until the listener fails or the server is shut down.
+// The context bounds the lifetime of all served connections, when it is done the listener
+// and all connections are closed and context error is returned.
+// Always returns non-nil error, ErrServerClosed after Shutdown.
+func (s *StreamServer) Serve(ctx context.Context) error {
+	stop := context.AfterFunc(ctx, s.close)
+	defer stop()
+	var retryDelay time.Duration
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if s.isClosed() {
+				return ErrServerClosed
+			}
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				retryDelay = min(max(2*retryDelay, 5*time.Millisecond), time.Second)
+				s.logger.Debug("jsonrpc2: accept error, retrying", "error", err, "delay", retryDelay)
+				time.Sleep(retryDelay)
+				continue
+			}
+			return err
+		}
+		retryDelay = 0
+		s.serveConnection(ctx, conn)
+	}
+}
+
+func (s *StreamServer) serveConnection(ctx context.Context, conn net.Conn) {
+	s.mutex.Lock()
+	if s.closed {
+		s.mutex.Unlock()
+		conn.Close()
+		return
+	}
+	s.served.Add(1)
+	s.mutex.Unlock()
+
+	s.logger.Debug("jsonrpc2: accepted connection", "remote", conn.RemoteAddr())
+	go func() {
+		defer s.served.Done()
+		endpoint := newStreamEndpoint(s.newStream(conn))
+		endpoint.methodRegistry = s.methods
+		endpoint.UseLogger(s.logger)
+		if onConnect, _ := s.getHooks(); onConnect != nil {
+			onConnect(endpoint)
+		}
+
+		s.mutex.Lock()
+		if s.closed {
+			s.mutex.Unlock()
+			endpoint.Close()
+			return
+		}
+		s.connections[endpoint] = struct{}{}
+		s.mutex.Unlock()
+		endpoint.start(ctx)
+
+		<-endpoint.GetOnCloseListener()
+		s.mutex.Lock()
+		delete(s.connections, endpoint)
+		s.mutex.Unlock()
+		s.logger.Debug("jsonrpc2: connection closed", "remote", conn.RemoteAddr())
+		if _, onDisconnect := s.getHooks(); onDisconnect != nil {
+			onDisconnect(endpoint)
+		}
+	}()
+}
+
+// close stops accepting new connections and closes all connections immediately
+func (s *StreamServer) close() {
+	s.mutex.Lock()
+	s.closed = true
+	s.listener.Close()
+	s.mutex.Unlock()
+	for _, endpoint := range s.Connections() {
+		endpoint.Close()
+	}
+}
+
+func (s *StreamServer) isClosed() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.closed
+}
+
+// Shutdown stops accepting new connections, waits for in-flight messages
+// of every connection to be processed and closes the connections.
+// If context expires first, remaining connections are closed immediately
+// and context error is returned.
+func (s *StreamServer) Shutdown(ctx context.Context) error {
+	s.mutex.Lock()
+	if s.closed {
+		s.mutex.Unlock()
+		return ErrServerClosed
+	}
+	s.closed = true
+	err := s.listener.Close()
+	s.mutex.Unlock()
+
+	for _, endpoint := range s.Connections() {
+		go endpoint.Shutdown(ctx)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		s.served.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return err
+	case <-ctx.Done():
+		for _, endpoint := range s.Connections() {
+			endpoint.Close()
+		}
+		<-done
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,206 @@
+package jsonrpc2
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func startStreamServer(t *testing.T, codec ObjectCodec) (*StreamServer, chan error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewStreamServer(listener, codec)
+	served := make(chan error, 1)
+	go func() {
+		served <- s.Serve(context.Background())
+	}()
+	return s, served
+}
+
+func dialStreamServer(t *testing.T, s *StreamServer, codec ObjectCodec) *StreamEndpoint {
+	conn, err := net.Dial("tcp", s.listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if codec == nil {
+		return NewStreamEndpoint(context.Background(), NewPlainObjectStream(conn))
+	}
+	return NewStreamEndpoint(context.Background(), NewBufferedStream(conn, codec))
+}
+
+func TestStreamServerRequest(t *testing.T) {
+	assert := assert.New(t)
+	for _, codec := range []ObjectCodec{nil, VSCodeObjectCodec{}, VarintObjectCodec{}} {
+		s, _ := startStreamServer(t, codec)
+		RegisterEndpointMethod(s, "hello", func(ctx context.Context, name string) (string, *Error) {
+			return "Hello " + name, nil
+		})
+
+		c1 := dialStreamServer(t, s, codec)
+		c2 := dialStreamServer(t, s, codec)
+		for _, c := range []*StreamEndpoint{c1, c2} {
+			r, err := Request[[]string, string](context.Background(), c, "hello", []string{"World"})
+			assert.Nil(err)
+			result, err := r.Unwrap()
+			assert.Nil(err)
+			assert.Equal("Hello World", result)
+		}
+		assert.Len(s.Connections(), 2)
+		assert.Nil(s.Shutdown(context.Background()))
+	}
+}
+
+func TestStreamServerConnectionHooks(t *testing.T) {
+	assert := assert.New(t)
+	s, _ := startStreamServer(t, nil)
+	var connected, disconnected atomic.Int32
+	disconnectedSignal := make(chan struct{}, 1)
+	s.OnConnect(func(endpoint *StreamEndpoint) {
+		connected.Add(1)
+	})
+	s.OnDisconnect(func(endpoint *StreamEndpoint) {
+		disconnected.Add(1)
+		disconnectedSignal <- struct{}{}
+	})
+	RegisterEndpointMethod(s, "hello", func(ctx context.Context, name string) (string, *Error) {
+		return "Hello " + name, nil
+	})
+
+	c := dialStreamServer(t, s, nil)
+	_, err := Request[[]string, string](context.Background(), c, "hello", []string{"World"})
+	assert.Nil(err)
+	assert.Equal(int32(1), connected.Load())
+	c.Close()
+
+	select {
+	case <-disconnectedSignal:
+	case <-time.After(5 * time.Second):
+		assert.Fail("no disconnect notification")
+	}
+	assert.Equal(int32(1), disconnected.Load())
+	assert.Len(s.Connections(), 0)
+}
+
+func TestStreamServerSlowConnectHook(t *testing.T) {
+	assert := assert.New(t)
+	s, _ := startStreamServer(t, nil)
+	blocked := make(chan struct{})
+	release := make(chan struct{})
+	var connected atomic.Int32
+	s.OnConnect(func(endpoint *StreamEndpoint) {
+		// the first connection blocks in the hook
+		if connected.Add(1) == 1 {
+			close(blocked)
+			<-release
+		}
+	})
+	RegisterEndpointMethod(s, "hello", func(ctx context.Context, name string) (string, *Error) {
+		return "Hello " + name, nil
+	})
+
+	dialStreamServer(t, s, nil)
+	<-blocked
+	c := dialStreamServer(t, s, nil)
+	response, err := Request[[]string, string](context.Background(), c, "hello", []string{"World"})
+	assert.Nil(err)
+	assert.Equal("Hello World", response.Result)
+	close(release)
+}
+
+func TestStreamServerContext(t *testing.T) {
+	assert := assert.New(t)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewStreamServer(listener, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() {
+		served <- s.Serve(ctx)
+	}()
+	RegisterEndpointMethod(s, "hello", func(ctx context.Context, name string) (string, *Error) {
+		return "Hello " + name, nil
+	})
+
+	clients := []*StreamEndpoint{dialStreamServer(t, s, nil), dialStreamServer(t, s, nil)}
+	for _, c := range clients {
+		_, err := Request[[]string, string](context.Background(), c, "hello", []string{"World"})
+		assert.Nil(err)
+	}
+	assert.Len(s.Connections(), 2)
+
+	cancel()
+	select {
+	case err := <-served:
+		assert.ErrorIs(err, context.Canceled)
+	case <-time.After(5 * time.Second):
+		assert.Fail("serve did not return")
+	}
+	assert.Eventually(func() bool { return len(s.Connections()) == 0 }, 5*time.Second, 10*time.Millisecond)
+	for _, c := range clients {
+		select {
+		case <-c.GetOnCloseListener():
+		case <-time.After(5 * time.Second):
+			assert.Fail("connection not closed")
+		}
+	}
+	_, err = net.Dial("tcp", listener.Addr().String())
+	assert.NotNil(err)
+}
+
+func TestStreamServerShutdown(t *testing.T) {
+	assert := assert.New(t)
+	s, served := startStreamServer(t, nil)
+	started := make(chan struct{})
+	RegisterEndpointMethod(s, "slow", func(ctx context.Context, name string) (string, *Error) {
+		close(started)
+		time.Sleep(500 * time.Millisecond)
+		return "done " + name, nil
+	})
+
+	c := dialStreamServer(t, s, nil)
+	responses := make(chan *Response[string], 1)
+	go func() {
+		r, _ := Request[[]string, string](context.Background(), c, "slow", []string{"work"})
+		responses <- r
+	}()
+	<-started
+
+	assert.Nil(s.Shutdown(context.Background()))
+	assert.ErrorIs(<-served, ErrServerClosed)
+	r := <-responses
+	if assert.NotNil(r) {
+		result, err := r.Unwrap()
+		assert.Nil(err)
+		assert.Equal("done work", result)
+	}
+	assert.Len(s.Connections(), 0)
+	assert.ErrorIs(s.Shutdown(context.Background()), ErrServerClosed)
+}
+
+func TestStreamServerShutdownTimeout(t *testing.T) {
+	assert := assert.New(t)
+	s, _ := startStreamServer(t, nil)
+	started := make(chan struct{})
+	RegisterEndpointMethod(s, "slow", func(ctx context.Context, name string) (string, *Error) {
+		close(started)
+		time.Sleep(2 * time.Second)
+		return "done " + name, nil
+	})
+
+	c := dialStreamServer(t, s, nil)
+	go Request[[]string, string](context.Background(), c, "slow", []string{"work"})
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(s.Shutdown(ctx), context.DeadlineExceeded)
+	assert.Len(s.Connections(), 0)
+}
@@ -116,7 +116,7 @@ func TestStreamRequestNonExistingMethod(t *testing.T) {
 	s.UseLogger(testLogger.Logger)
 	c := NewStreamEndpoint(context.Background(), NewPlainObjectStream(connB))
 
-	response, err := Request[string, string](context.Background(), c, "test", "test data")
+	response, err := Request[[]string, string](context.Background(), c, "test", []string{"test data"})
 	assert.Nil(err)
 	assert.NotNil(response.Error)
 
@@ -135,7 +135,7 @@ func TestStreamRequest(t *testing.T) {
 		return "hello " + data, nil
 	})
 
-	response, err := Request[string, string](context.Background(), c, "test", "world")
+	response, err := Request[[]string, string](context.Background(), c, "test", []string{"world"})
 	assert.Nil(err)
 	assert.Nil(response.Error)
 
@@ -152,7 +152,7 @@ func TestStreamNotifyNonExistingMethod(t *testing.T) {
 	s.UseLogger(testLogger.Logger)
 	c := NewStreamEndpoint(context.Background(), NewPlainObjectStream(connB))
 
-	err := Notify(context.Background(), c, "test", "test data")
+	err := Notify(context.Background(), c, "test", []string{"test data"})
 	assert.Nil(err)
 }
 
@@ -172,7 +172,7 @@ func TestStreamNotify(t *testing.T) {
 		return "hello " + data, nil
 	})
 
-	err := Notify(context.Background(), c, "test", "world")
+	err := Notify(context.Background(), c, "test", []string{"world"})
 	assert.Nil(err)
 	select {
 	case <-signaled:
@@ -189,12 +189,12 @@ func TestStreamBatchNonExistingMethod(t *testing.T) {
 	s.UseLogger(testLogger.Logger)
 	c := NewStreamEndpoint(context.Background(), NewPlainObjectStream(connB))
 
-	requests := []RequestInfo[string]{
-		{Method: "test", Params: "world"},
-		{Method: "test", Params: "universe"},
+	requests := []RequestInfo[[]string]{
+		{Method: "test", Params: []string{"world"}},
+		{Method: "test", Params: []string{"universe"}},
 	}
 
-	responses, err := Batch[string, string](context.Background(), c, requests)
+	responses, err := Batch[[]string, string](context.Background(), c, requests)
 	assert.Nil(err)
 	for _, response := range responses {
 		assert.NotNil(response.Error)
@@ -214,11 +214,11 @@ func TestStreamBatch(t *testing.T) {
 		return "hello " + data, nil
 	})
 
-	requests := []RequestInfo[string]{
-		{Method: "test", Params: "world"},
-		{Method: "test", Params: "universe"},
+	requests := []RequestInfo[[]string]{
+		{Method: "test", Params: []string{"world"}},
+		{Method: "test", Params: []string{"universe"}},
 	}
-	responses, err := Batch[string, string](context.Background(), c, requests)
+	responses, err := Batch[[]string, string](context.Background(), c, requests)
 	assert.Nil(err)
 	for _, response := range responses {
 		assert.Nil(response.Error)
@@ -238,6 +238,7 @@ func TestReadMessagesInvalidMessage(t *testing.T) {
 	s := NewStreamEndpoint(context.Background(), NewPlainObjectStream(connA))
 	testLogger := test.NewLogger()
 	s.UseLogger(testLogger.Logger)
+	s.UseValidation(LenientValidation)
 	connB.Write([]byte("{ \"id\": 1, \"method\": \"test\", \"params\": \"hello world\", \"error\": {}, \"result\": \"hello world\" }"))
 	connB.Close()
 	time.Sleep(2 * time.Second) // wait for logs to be written
@@ -294,3 +295,86 @@ func TestStreamRequestNotRegisteredAsPending(t *testing.T) {
 	}()
 	assert.Contains(lastLog, "ignoring response")
 }
+
+func TestStreamRequestCancellation(t *testing.T) {
+	assert := assert.New(t)
+	connA, connB := net.Pipe()
+	s := NewStreamEndpoint(context.Background(), NewBufferedStream(connA, VSCodeObjectCodec{}))
+	s.UseRequestCancellation("")
+	c := NewStreamEndpoint(context.Background(), NewBufferedStream(connB, VSCodeObjectCodec{}))
+	c.UseRequestCancellation("")
+
+	cancelled := make(chan error, 1)
+	RegisterEndpointMethod(s, "wait", func(ctx context.Context, data string) (string, *Error) {
+		select {
+		case <-ctx.Done():
+			cancelled <- ctx.Err()
+		case <-time.After(5 * time.Second):
+			cancelled <- nil
+		}
+		return "", nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	_, err := Request[[]string, string](ctx, c, "wait", []string{"data"})
+	assert.ErrorIs(err, context.DeadlineExceeded)
+	assert.ErrorIs(<-cancelled, context.Canceled)
+}
+
+func TestStreamQueuedRequestCancellation(t *testing.T) {
+	assert := assert.New(t)
+	connA, connB := net.Pipe()
+	s := NewStreamEndpoint(context.Background(), NewPlainObjectStream(connA))
+	s.UseRequestCancellation("")
+	s.UseConcurrencyLimits(ConcurrencyLimits{MaxHandlers: 1, MaxQueued: 1})
+	c := NewStreamEndpoint(context.Background(), NewPlainObjectStream(connB))
+	c.UseRequestCancellation("")
+
+	release := make(chan struct{})
+	RegisterEndpointMethod(s, "block", func(ctx context.Context, data string) (string, *Error) {
+		<-release
+		return "released", nil
+	})
+	cancelled := make(chan error, 1)
+	RegisterEndpointMethod(s, "queued", func(ctx context.Context, data string) (string, *Error) {
+		cancelled <- ctx.Err()
+		return "", nil
+	})
+
+	blocked := Go[[]string, string](context.Background(), c, "block", []string{"data"})
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err := Request[[]string, string](ctx, c, "queued", []string{"data"})
+	assert.ErrorIs(err, context.DeadlineExceeded)
+
+	close(release)
+	response, err := blocked.Wait(context.Background())
+	assert.Nil(err)
+	assert.Equal("released", response.Result)
+	assert.ErrorIs(<-cancelled, context.Canceled)
+}
+
+func TestStreamRequestCancellationDisabled(t *testing.T) {
+	assert := assert.New(t)
+	connA, connB := net.Pipe()
+	s := NewStreamEndpoint(context.Background(), NewPlainObjectStream(connA))
+	c := NewStreamEndpoint(context.Background(), NewPlainObjectStream(connB))
+
+	cancelled := make(chan error, 1)
+	RegisterEndpointMethod(s, "wait", func(ctx context.Context, data string) (string, *Error) {
+		select {
+		case <-ctx.Done():
+			cancelled <- ctx.Err()
+		case <-time.After(500 * time.Millisecond):
+			cancelled <- nil
+		}
+		return "", nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err := Request[[]string, string](ctx, c, "wait", []string{"data"})
+	assert.ErrorIs(err, context.DeadlineExceeded)
+	assert.Nil(<-cancelled)
+}
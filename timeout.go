@@ -0,0 +1,165 @@
+package jsonrpc2
+
+import (
+	"context"
+	"encoding/json"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// DefaultTimeoutErrorCode is code of request timeout error returned when handler does not finish in time
+const DefaultTimeoutErrorCode = -32001
+
+// DeadlineMetaField is field of request meta carrying deadline of the client as RFC 3339 timestamp
+const DeadlineMetaField = "deadline"
+
+// WithTimeout bounds run time of the method handler. Handler context gets the deadline and if
+// the handler does not return in time, request timeout error is returned in its place.
+// Handler keeps running in the background until it returns, so it should honor the context.
+// StreamEndpoint counts such handler as running until it returns, it holds its concurrency
+// slot and Shutdown waits for it.
+func WithTimeout(timeout time.Duration) MethodOption {
+	return func(o *methodOptions) {
+		o.timeout = timeout
+	}
+}
+
+// WithTimeoutErrorCode sets code of request timeout error of the method, DefaultTimeoutErrorCode by default.
+// The code is used for timeouts of both WithTimeout and client deadlines.
+func WithTimeoutErrorCode(code int) MethodOption {
+	return func(o *methodOptions) {
+		o.timeoutCode = code
+	}
+}
+
+// UseClientDeadlines honors deadlines sent by clients in the "deadline" field of request meta.
+// Requests not handled before the deadline are responded with request timeout error.
+func (c *serverConfig) UseClientDeadlines() {
+	c.clientDeadlines = true
+}
+
+// PropagateDeadline sends deadline of the request context in the "deadline" field of request meta,
+// so servers with client deadlines enabled can stop processing requests the client is not waiting for.
+func PropagateDeadline() ClientInterceptor {
+	return func(ctx context.Context, calls []*ClientCall, next ClientInvoker) ([]*Response[json.RawMessage], error) {
+		deadline, ok := ctx.Deadline()
+		if !ok {
+			return next(ctx, calls)
+		}
+		for _, call := range calls {
+			if call.IsNotification {
+				continue
+			}
+			if call.Meta == nil {
+				call.Meta = make(map[string]interface{}, 1)
+			}
+			call.Meta[DeadlineMetaField] = deadline.UTC().Format(time.RFC3339Nano)
+		}
+		return next(ctx, calls)
+	}
+}
+
+func getClientDeadline(rpcMsg *Message) (time.Time, bool) {
+	if len(rpcMsg.Meta) == 0 {
+		return time.Time{}, false
+	}
+	var meta map[string]json.RawMessage
+	if err := json.Unmarshal(rpcMsg.Meta, &meta); err != nil {
+		return time.Time{}, false
+	}
+	var deadline time.Time
+	if err := json.Unmarshal(meta[DeadlineMetaField], &deadline); err != nil {
+		return time.Time{}, false
+	}
+	return deadline, true
+}
+
+// getTimeoutCode returns code of request timeout error of the method
+func getTimeoutCode(reg RpcMethodRegistry, method string) int {
+	if entry := getMethodEntry(reg, method); entry != nil && entry.timeoutCode != 0 {
+		return entry.timeoutCode
+	}
+	return DefaultTimeoutErrorCode
+}
+
+// outlivingHandlers tracks handlers which keep running after their request timed out
+type outlivingHandlers struct {
+	sync.WaitGroup
+}
+
+func withOutlivingHandlers(ctx context.Context, handlers *outlivingHandlers) context.Context {
+	return context.WithValue(ctx, outlivingHandlersContextKey, handlers)
+}
+
+func withTimeout(timeout time.Duration, code int, handler RpcHandler) RpcHandler {
+	if code == 0 {
+		code = DefaultTimeoutErrorCode
+	}
+	return func(ctx context.Context, rpcMsg *Message) interface{} {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		return runWithDeadline(ctx, rpcMsg, handler, code)
+	}
+}
+
+type handlerResult struct {
+	response  interface{}
+	recovered interface{}
+	stack     []byte
+}
+
+// runWithDeadline runs handler and returns request timeout error if deadline of ctx passes before it returns.
+// Panic of the handler is reported by the endpoint with stack of the handler and converted to internal error.
+func runWithDeadline(ctx context.Context, rpcMsg *Message, handler RpcHandler, code int) interface{} {
+	if ctx.Err() == context.DeadlineExceeded {
+		return timeoutResponse(rpcMsg, code)
+	}
+	done := make(chan handlerResult, 1)
+	go func() {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				done <- handlerResult{recovered: recovered, stack: debug.Stack()}
+			}
+		}()
+		done <- handlerResult{response: handler(ctx, rpcMsg)}
+	}()
+
+	select {
+	case result := <-done:
+		return result.get(ctx, rpcMsg)
+	case <-ctx.Done():
+		if ctx.Err() != context.DeadlineExceeded {
+			// cancelled requests are responded by the handler
+			result := <-done
+			return result.get(ctx, rpcMsg)
+		}
+		outliving, _ := ctx.Value(outlivingHandlersContextKey).(*outlivingHandlers)
+		if outliving != nil {
+			outliving.Add(1)
+		}
+		go func() {
+			if result := <-done; result.recovered != nil {
+				reportLatePanic(ctx, rpcMsg, result.recovered, result.stack)
+			}
+			if outliving != nil {
+				outliving.Done()
+			}
+		}()
+		return timeoutResponse(rpcMsg, code)
+	}
+}
+
+func (r handlerResult) get(ctx context.Context, rpcMsg *Message) interface{} {
+	if r.recovered != nil {
+		return recoverHandlerPanic(ctx, rpcMsg, r.recovered, r.stack)
+	}
+	return r.response
+}
+
+func timeoutResponse(rpcMsg *Message, code int) interface{} {
+	if rpcMsg.Id == nil {
+		return nil
+	}
+	return NewRequestTimeout(code).ToResponse(rpcMsg.Id)
+}
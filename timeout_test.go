@@ -0,0 +1,207 @@
+package jsonrpc2
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func waitForDeadline(ctx context.Context, name string) (string, *Error) {
+	select {
+	case <-ctx.Done():
+		return "", NewInternalErrorWithData(ctx.Err().Error())
+	case <-time.After(5 * time.Second):
+		return "Hello " + name, nil
+	}
+}
+
+func hasDeadline(ctx context.Context, _ string) (string, *Error) {
+	if _, ok := ctx.Deadline(); ok {
+		return "deadline", nil
+	}
+	return "none", nil
+}
+
+func TestStreamMethodTimeout(t *testing.T) {
+	assert := assert.New(t)
+	connA, connB := net.Pipe()
+	s := NewStreamEndpoint(context.Background(), NewPlainObjectStream(connA))
+	c := NewStreamEndpoint(context.Background(), NewPlainObjectStream(connB))
+	RegisterEndpointMethod(s, "wait", waitForDeadline, WithTimeout(50*time.Millisecond))
+	RegisterEndpointMethod(s, "waitCustom", waitForDeadline, WithTimeout(50*time.Millisecond), WithTimeoutErrorCode(-32010))
+	RegisterEndpointMethod(s, "hello", func(ctx context.Context, name string) (string, *Error) {
+		return "Hello " + name, nil
+	}, WithTimeout(time.Second))
+
+	response, err := Request[[]string, string](context.Background(), c, "wait", []string{"World"})
+	assert.Nil(err)
+	if assert.NotNil(response.Error) {
+		assert.Equal(DefaultTimeoutErrorCode, response.Error.Code)
+		assert.Equal("Request timeout", response.Error.Message)
+	}
+
+	response, err = Request[[]string, string](context.Background(), c, "waitCustom", []string{"World"})
+	assert.Nil(err)
+	if assert.NotNil(response.Error) {
+		assert.Equal(-32010, response.Error.Code)
+	}
+
+	response, err = Request[[]string, string](context.Background(), c, "hello", []string{"World"})
+	assert.Nil(err)
+	assert.Equal("Hello World", response.Result)
+}
+
+func TestHttpMethodTimeout(t *testing.T) {
+	assert := assert.New(t)
+	mux := NewServerMux()
+	RegisterServerMuxEndpointMethod(mux, "/", "wait", waitForDeadline, WithTimeout(50*time.Millisecond))
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	response, err := Request[[]string, string](context.Background(), NewHttpClientEndpoint(srv.URL, nil), "wait", []string{"World"})
+	assert.Nil(err)
+	if assert.NotNil(response.Error) {
+		assert.Equal(DefaultTimeoutErrorCode, response.Error.Code)
+	}
+}
+
+func TestLatePanicReported(t *testing.T) {
+	assert := assert.New(t)
+	mux := NewServerMux()
+	panics := make(chan interface{}, 1)
+	mux.OnPanic(func(ctx context.Context, method string, recovered interface{}, stack []byte) {
+		assert.Equal("late", method)
+		assert.Contains(string(stack), "TestLatePanicReported")
+		panics <- recovered
+	})
+	RegisterServerMuxEndpointMethod(mux, "/", "late", func(ctx context.Context, name string) (string, *Error) {
+		<-ctx.Done()
+		panic("late boom")
+	}, WithTimeout(50*time.Millisecond))
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	response, err := Request[[]string, string](context.Background(), NewHttpClientEndpoint(srv.URL, nil), "late", []string{"World"})
+	assert.Nil(err)
+	if assert.NotNil(response.Error) {
+		assert.Equal(DefaultTimeoutErrorCode, response.Error.Code)
+	}
+	select {
+	case recovered := <-panics:
+		assert.Equal("late boom", recovered)
+	case <-time.After(time.Second):
+		assert.Fail("late panic was not reported")
+	}
+}
+
+func TestStreamTimedOutHandlerHoldsSlot(t *testing.T) {
+	assert := assert.New(t)
+	connA, connB := net.Pipe()
+	release := make(chan struct{})
+	s := NewStreamEndpoint(context.Background(), NewPlainObjectStream(connA), func(s *StreamEndpoint) {
+		s.UseConcurrencyLimits(ConcurrencyLimits{MaxHandlers: 1})
+		RegisterEndpointMethod(s, "stuck", func(ctx context.Context, name string) (string, *Error) {
+			<-release
+			return "Hello " + name, nil
+		}, WithTimeout(50*time.Millisecond))
+	})
+	c := NewStreamEndpoint(context.Background(), NewPlainObjectStream(connB))
+
+	response, err := Request[[]string, string](context.Background(), c, "stuck", []string{"World"})
+	assert.Nil(err)
+	if assert.NotNil(response.Error) {
+		assert.Equal(DefaultTimeoutErrorCode, response.Error.Code)
+	}
+	// handler still runs, so it keeps its slot and shutdown waits for it
+	assert.Equal(1, s.ConcurrencyStats().ActiveHandlers)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(s.drain(ctx), context.DeadlineExceeded)
+
+	close(release)
+	assert.Nil(s.drain(context.Background()))
+	assert.Eventually(func() bool { return s.ConcurrencyStats().ActiveHandlers == 0 }, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestTimeoutPanicStack(t *testing.T) {
+	assert := assert.New(t)
+	mux := NewServerMux()
+	mux.UsePanicStackTrace()
+	panics := make(chan []byte, 1)
+	mux.OnPanic(func(ctx context.Context, method string, recovered interface{}, stack []byte) {
+		assert.Equal("boom", recovered)
+		panics <- stack
+	})
+	RegisterServerMuxEndpointMethod(mux, "/", "panic", func(ctx context.Context, name string) (string, *Error) {
+		panic("boom")
+	}, WithTimeout(time.Second))
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	response, err := Request[[]string, string](context.Background(), NewHttpClientEndpoint(srv.URL, nil), "panic", []string{"World"})
+	assert.Nil(err)
+	if assert.NotNil(response.Error) {
+		assert.Equal(-32603, response.Error.Code)
+		var data PanicData
+		assert.Nil(json.Unmarshal(*response.Error.Data, &data))
+		assert.Equal("boom", data.Panic)
+		// stack of the handler, not of the goroutine waiting for it
+		assert.Contains(data.Stack, "TestTimeoutPanicStack")
+	}
+	assert.Contains(string(<-panics), "TestTimeoutPanicStack")
+}
+
+func TestStreamClientDeadlines(t *testing.T) {
+	assert := assert.New(t)
+	connA, connB := net.Pipe()
+	s := NewStreamEndpoint(context.Background(), NewPlainObjectStream(connA))
+	c := NewStreamEndpoint(context.Background(), NewPlainObjectStream(connB))
+	c.UseInterceptors(PropagateDeadline())
+	RegisterEndpointMethod(s, "hasDeadline", hasDeadline)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	response, err := Request[[]string, string](ctx, c, "hasDeadline", []string{""})
+	assert.Nil(err)
+	assert.Equal("none", response.Result)
+
+	s.UseClientDeadlines()
+	response, err = Request[[]string, string](ctx, c, "hasDeadline", []string{""})
+	assert.Nil(err)
+	assert.Equal("deadline", response.Result)
+
+	response, err = Request[[]string, string](context.Background(), c, "hasDeadline", []string{""})
+	assert.Nil(err)
+	assert.Equal("none", response.Result)
+}
+
+func TestExpiredClientDeadline(t *testing.T) {
+	assert := assert.New(t)
+	reg := NewMethodRegistry()
+	called := false
+	RegisterMethod(reg, "hello", func(ctx context.Context, name string) (string, *Error) {
+		called = true
+		return "Hello " + name, nil
+	})
+
+	meta, _ := json.Marshal(map[string]interface{}{DeadlineMetaField: time.Now().Add(-time.Second)})
+	response, err := json.Marshal(processRpcRequest(context.Background(), reg, &Message{Id: &ID{"1"}, Method: "hello", Meta: meta}, nil, &serverConfig{clientDeadlines: true}, nil))
+	assert.Nil(err)
+	assert.JSONEq(`{"jsonrpc": "2.0", "id": "1", "error": {"code": -32001, "message": "Request timeout"}}`, string(response))
+	assert.False(called)
+
+	// code of the method is used for client deadlines too
+	RegisterMethod(reg, "custom", func(ctx context.Context, name string) (string, *Error) {
+		called = true
+		return "Hello " + name, nil
+	}, WithTimeoutErrorCode(-32010))
+	response, err = json.Marshal(processRpcRequest(context.Background(), reg, &Message{Id: &ID{"1"}, Method: "custom", Meta: meta}, nil, &serverConfig{clientDeadlines: true}, nil))
+	assert.Nil(err)
+	assert.JSONEq(`{"jsonrpc": "2.0", "id": "1", "error": {"code": -32010, "message": "Request timeout"}}`, string(response))
+	assert.False(called)
+}
@@ -30,28 +30,62 @@ type messageBase struct {
 	Version string `json:"jsonrpc"`
 }
 
-type message struct {
+// Message is JSON-RPC message as received by the endpoint. Handlers and middlewares
+// get the request through it, params are left undecoded.
+type Message struct {
 	messageBase
-	Id     interface{}     `json:"id,omitempty"`
+	Id     *ID             `json:"id,omitempty"`
 	Method string          `json:"method,omitempty"`
 	Params json.RawMessage `json:"params,omitempty"`
 	Result json.RawMessage `json:"result,omitempty"`
 	Error  *ErrorObj       `json:"error,omitempty"`
+	Meta   json.RawMessage `json:"meta,omitempty"`
+
+	// invalid is error of decoding the message, it is reported as invalid request
+	invalid error
+}
+
+func (r *Message) UnmarshalJSON(data []byte) error {
+	type plainMessage Message
+	msg := struct {
+		*plainMessage
+		// decoded separately so null id can be told apart from missing one
+		Id json.RawMessage `json:"id,omitempty"`
+	}{plainMessage: (*plainMessage)(r)}
+	err := json.Unmarshal(data, &msg)
+	if msg.Id != nil {
+		r.Id = &ID{}
+		if idErr := json.Unmarshal(msg.Id, r.Id); idErr != nil && err == nil {
+			err = idErr
+		}
+	}
+	return err
+}
+
+// decode decodes message, message which can not be decoded is marked invalid
+func (r *Message) decode(data []byte) {
+	if err := json.Unmarshal(data, r); err != nil {
+		id := r.Id
+		if !id.isValid() {
+			id = nil
+		}
+		*r = Message{Id: id, invalid: err}
+	}
 }
 
-func (r *message) IsRequest() bool {
+func (r *Message) IsRequest() bool {
 	return r.Method != ""
 }
 
-func (r *message) IsSuccessResponse() bool {
+func (r *Message) IsSuccessResponse() bool {
 	return r.Result != nil
 }
 
-func (r *message) IsErrorResponse() bool {
+func (r *Message) IsErrorResponse() bool {
 	return r.Error != nil
 }
 
-func (r *message) isNonDeterminableKind() bool {
+func (r *Message) isNonDeterminableKind() bool {
 	matchedKinds := []bool{
 		r.IsRequest(),
 		r.IsSuccessResponse(),
@@ -66,7 +100,17 @@ func (r *message) isNonDeterminableKind() bool {
 	return truthy != 1
 }
 
-func (r *message) GetKind() (MessageKind, error) {
+// GetKind determines kind of the message and validates it against JSON-RPC 2.0 specification
+func (r *Message) GetKind() (MessageKind, error) {
+	return r.getKind(true)
+}
+
+// getKind determines kind of the message. Lenient mode accepts params which are not array
+// or object and treats requests with null id as notifications.
+func (r *Message) getKind(strict bool) (MessageKind, error) {
+	if r.invalid != nil {
+		return INVALID_KIND, r.invalid
+	}
 	if r.Version != jsonRpcVersion {
 		return INVALID_KIND, fmt.Errorf("invalid jsonrpc version: %s", r.Version)
 	}
@@ -77,7 +121,13 @@ func (r *message) GetKind() (MessageKind, error) {
 		if r.Method == "" {
 			return INVALID_KIND, ErrInternalMethodRequired
 		}
-		if r.Id == nil {
+		if strict && r.Params != nil && !isStructuredJson(r.Params) {
+			return INVALID_KIND, ErrInternalInvalidParams
+		}
+		if r.Id != nil && !r.Id.IsNull() && !r.Id.isValid() {
+			return INVALID_KIND, fmt.Errorf("invalid id type: %T", r.Id.value)
+		}
+		if r.Id == nil || (!strict && r.Id.IsNull()) {
 			return NOTIFICATION_KIND, nil
 		}
 		return REQUEST_KIND, nil
@@ -86,10 +136,12 @@ func (r *message) GetKind() (MessageKind, error) {
 		if r.Id == nil {
 			return INVALID_KIND, errors.New("id is required")
 		}
-		switch r.Id.(type) {
-		case string, int64, int32, int16, int8, int, uint64, uint32, uint16, uint8, uint:
-		default:
-			return INVALID_KIND, fmt.Errorf("invalid id type: %T", r.Id)
+		// null id is used in errors of requests whose id could not be determined
+		if r.Id.IsNull() && r.IsSuccessResponse() {
+			return INVALID_KIND, errors.New("id of success response must not be null")
+		}
+		if !r.Id.IsNull() && !r.Id.isValid() {
+			return INVALID_KIND, fmt.Errorf("invalid id type: %T", r.Id.value)
 		}
 	}
 	if r.IsSuccessResponse() {
@@ -102,25 +154,48 @@ func (r *message) GetKind() (MessageKind, error) {
 	return INVALID_KIND, ErrInternalInvalidMessageStructure
 }
 
-func MessageToRequest[TParam Params](r *message) *request[TParam] {
-	method := r.Method
+// idKey normalizes id so it can be used as a map key, received *ID and id of the same value
+// sent by the endpoint have the same key
+func idKey(id interface{}) string {
+	key, _ := json.Marshal(id)
+	return string(key)
+}
+
+// MessageToRequest converts message to request with decoded params.
+// Returns nil if params can not be decoded, use DecodeRequest to get the error.
+func MessageToRequest[TParam Params](r *Message) *request[TParam] {
+	request, err := decodeRequest[TParam](r, false)
+	if err != nil {
+		return nil
+	}
+	return request
+}
+
+// DecodeRequest converts message to request with decoded params. If params can not be decoded
+// invalid params error with ParamsDecodingError in data is returned. In strict mode unknown
+// fields of params are rejected.
+func DecodeRequest[TParam Params](r *Message, strict bool) (*request[TParam], *Error) {
+	return decodeRequest[TParam](r, strict)
+}
+
+func decodeRequest[TParam Params](r *Message, strict bool) (*request[TParam], *Error) {
 	var params TParam
-	if r.Params != nil {
-		err := json.Unmarshal(r.Params, &params)
-		if err != nil {
-			return nil
-		}
+	if err := decodeParams(r.Params, &params, strict); err != nil {
+		return nil, err
 	}
 
-	return &request[TParam]{
+	request := &request[TParam]{
 		messageBase: messageBase{Version: jsonRpcVersion},
-		Id:          r.Id,
-		Method:      method,
+		Method:      r.Method,
 		Params:      params,
 	}
+	if r.Id != nil {
+		request.Id = r.Id
+	}
+	return request, nil
 }
 
-func MessageToSuccessResponse[TResult Result](rpc *message) (*successResponse[TResult], error) {
+func MessageToSuccessResponse[TResult Result](rpc *Message) (*successResponse[TResult], error) {
 	if !rpc.IsSuccessResponse() {
 		return nil, errors.New("invalid rpc message type - not a success response")
 	}
@@ -134,26 +209,26 @@ func MessageToSuccessResponse[TResult Result](rpc *message) (*successResponse[TR
 
 	return &successResponse[TResult]{
 		messageBase{Version: jsonRpcVersion},
-		rpc.Id,
+		rpc.Id.Value(),
 		result,
 		nil,
 	}, nil
 }
 
-func MessageToErrorResponse(rpc *message) (*errorResponse, error) {
+func MessageToErrorResponse(rpc *Message) (*errorResponse, error) {
 	if !rpc.IsSuccessResponse() {
 		return nil, errors.New("invalid rpc message type - not a success response")
 	}
 
 	return &errorResponse{
 		messageBase{Version: jsonRpcVersion},
-		rpc.Id,
+		rpc.Id.Value(),
 		nil,
 		rpc.Error,
 	}, nil
 }
 
-func MessageToResponse[TResult Result](rpc *message) (*Response[TResult], error) {
+func MessageToResponse[TResult Result](rpc *Message) (*Response[TResult], error) {
 	kind, err := rpc.GetKind()
 	if err != nil {
 		return nil, err
@@ -161,7 +236,7 @@ func MessageToResponse[TResult Result](rpc *message) (*Response[TResult], error)
 
 	response := &Response[TResult]{
 		messageBase: messageBase{Version: jsonRpcVersion},
-		Id:          rpc.Id,
+		Id:          rpc.Id.Value(),
 	}
 
 	switch kind {
@@ -183,7 +258,7 @@ func MessageToResponse[TResult Result](rpc *message) (*Response[TResult], error)
 
 // Object is a wrapper for a single or batch of rpc messages
 type Object struct {
-	messages []message
+	messages []Message
 	isBatch  bool
 }
 
@@ -191,25 +266,41 @@ func (r *Object) IsBatch() bool {
 	return r.isBatch
 }
 
-func (r *Object) GetMessages() []message {
+func (r *Object) GetMessages() []Message {
 	return r.messages
 }
 
-func (r *Object) GetSingleMessage() *message {
+func (r *Object) GetSingleMessage() *Message {
 	if r.isBatch {
 		return nil
 	}
 	return &r.messages[0]
 }
 
+// UnmarshalJSON decodes single message or batch. Messages which are not valid request
+// or response objects, e.g. numbers, are kept and reported as invalid by GetKind.
 func (r *Object) UnmarshalJSON(data []byte) error {
 	if data[0] == '[' {
+		var rawMessages []json.RawMessage
+		if err := json.Unmarshal(data, &rawMessages); err != nil {
+			return err
+		}
 		r.isBatch = true
-		return json.Unmarshal(data, &r.messages)
+		r.messages = make([]Message, len(rawMessages))
+		for i, rawMessage := range rawMessages {
+			r.messages[i].decode(rawMessage)
+		}
+		return nil
 	}
 	r.isBatch = false
-	r.messages = make([]message, 1)
-	return json.Unmarshal(data, &r.messages[0])
+	r.messages = make([]Message, 1)
+	r.messages[0].decode(data)
+	return nil
+}
+
+// IsEmptyBatch reports whether the object is batch without messages, which is invalid request
+func (r *Object) IsEmptyBatch() bool {
+	return r.isBatch && len(r.messages) == 0
 }
 
 func (r *Object) MarshalJSON() ([]byte, error) {
@@ -0,0 +1,127 @@
+package jsonrpc2
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"reflect"
+)
+
+// ValidationMode decides how strictly messages are checked against JSON-RPC 2.0 specification
+type ValidationMode int
+
+const (
+	// StrictValidation is the default mode. Requests with params other than array or object are
+	// rejected as invalid request, requests with null id are answered, empty batch is answered
+	// with invalid request and invalid messages in batch get invalid request responses.
+	// Clients refuse to send params which are not array or object, scalar param has to be sent
+	// as array with single element, methods with scalar param type accept it.
+	StrictValidation ValidationMode = iota
+	// LenientValidation accepts legacy peers. Any params are accepted and sent as they are,
+	// requests with null id are treated as notifications, empty batches and invalid messages
+	// received by StreamEndpoint are ignored.
+	LenientValidation
+)
+
+// protocolConfig holds validation mode and protocol version shared by client and server endpoints
+type protocolConfig struct {
+	validation ValidationMode
+	version    ProtocolVersion
+}
+
+// UseValidation sets how strictly messages sent and received by the endpoint follow the specification.
+// Should be set before the first message is sent or received, stream endpoints are configured
+// in ConnOpt passed to NewStreamEndpoint.
+func (c *protocolConfig) UseValidation(mode ValidationMode) {
+	c.validation = mode
+}
+
+func (c *protocolConfig) isStrict() bool {
+	return c.validation == StrictValidation
+}
+
+func (c *protocolConfig) getProtocolConfig() *protocolConfig {
+	return c
+}
+
+type protocolEndpoint interface {
+	getProtocolConfig() *protocolConfig
+}
+
+// isStrictEndpoint reports whether the endpoint validates messages strictly, endpoints without
+// validation config are strict
+func isStrictEndpoint(c interface{}) bool {
+	if endpoint, ok := c.(protocolEndpoint); ok {
+		return endpoint.getProtocolConfig().isStrict()
+	}
+	return true
+}
+
+func isStructuredJson(data json.RawMessage) bool {
+	data = bytes.TrimSpace(data)
+	return len(data) > 0 && (data[0] == '[' || data[0] == '{')
+}
+
+// structuredParams returns params encoded as array or object, params encoding to other
+// JSON values are rejected with ErrInternalInvalidParams
+func structuredParams(params interface{}) (interface{}, error) {
+	if params == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.Equal(data, []byte("null")) {
+		return nil, nil
+	}
+	if isStructuredJson(data) {
+		return json.RawMessage(data), nil
+	}
+	return nil, ErrInternalInvalidParams
+}
+
+var paramsDecoderType = reflect.TypeFor[ParamsDecoder]()
+
+// isScalarParamType reports whether params of the type do not decode from array or object
+func isScalarParamType(t reflect.Type) bool {
+	if t == nil || reflect.PointerTo(t).Implements(paramsDecoderType) {
+		return false
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Implements(reflect.TypeFor[json.Unmarshaler]()) || reflect.PointerTo(t).Implements(reflect.TypeFor[json.Unmarshaler]()) {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array, reflect.Interface:
+		return false
+	}
+	return true
+}
+
+// withScalarParams unwraps param sent as array with single element for methods with scalar param type
+func withScalarParams(handler RpcHandler) RpcHandler {
+	return func(ctx context.Context, rpcMsg *Message) interface{} {
+		if !isJsonArray(rpcMsg.Params) {
+			return handler(ctx, rpcMsg)
+		}
+		var values []json.RawMessage
+		if err := json.Unmarshal(rpcMsg.Params, &values); err != nil || len(values) != 1 {
+			return handler(ctx, rpcMsg)
+		}
+		scalarMsg := *rpcMsg
+		scalarMsg.Params = values[0]
+		return handler(ctx, &scalarMsg)
+	}
+}
+
+// invalidMessageResponse returns invalid request error response to message which is not valid request,
+// nil for responses
+func invalidMessageResponse(rpcMsg *Message, err error) interface{} {
+	if rpcMsg.IsRequest() || (!rpcMsg.IsSuccessResponse() && !rpcMsg.IsErrorResponse()) {
+		return NewInvalidRequestWithData(err.Error()).ToResponse(rpcMsg.Id)
+	}
+	return nil
+}
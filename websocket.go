@@ -0,0 +1,156 @@
+package jsonrpc2
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"nhooyr.io/websocket"
+)
+
+// webSocketObjectStream reads/writes JSON-RPC 2.0 objects as websocket text messages.
+type webSocketObjectStream struct {
+	ctx  context.Context
+	conn *websocket.Conn
+}
+
+// NewWebSocketObjectStream creates stream from a websocket connection.
+// Every JSON-RPC 2.0 object is sent as a single text message. The context
+// bounds all reads and writes on the connection.
+func NewWebSocketObjectStream(ctx context.Context, conn *websocket.Conn) ObjectStream {
+	return &webSocketObjectStream{
+		ctx:  ctx,
+		conn: conn,
+	}
+}
+
+// WriteObject implements ObjectStream.
+func (s *webSocketObjectStream) WriteObject(obj interface{}) error {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	return s.conn.Write(s.ctx, websocket.MessageText, data)
+}
+
+// ReadObject implements ObjectStream.
+func (s *webSocketObjectStream) ReadObject(v interface{}) error {
+	_, data, err := s.conn.Read(s.ctx)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// Ping implements Pinger.
+func (s *webSocketObjectStream) Ping(ctx context.Context) error {
+	return s.conn.Ping(ctx)
+}
+
+// Close implements ObjectStream.
+func (s *webSocketObjectStream) Close() error {
+	return s.conn.Close(websocket.StatusNormalClosure, "")
+}
+
+// WithWebSocketReadLimit sets maximum size of a message read from websocket stream of the endpoint,
+// 32768 bytes by default, -1 disables the limit. Larger message closes the connection.
+// Ignored by endpoints over other streams.
+func WithWebSocketReadLimit(limit int64) ConnOpt {
+	return func(c *StreamEndpoint) {
+		if stream, ok := c.stream.(*webSocketObjectStream); ok {
+			stream.conn.SetReadLimit(limit)
+		}
+	}
+}
+
+// DialWebSocket connects to the websocket server and returns endpoint configured by opts
+// which can be used both for requests to server and for serving requests from server.
+// The context bounds only the dial, e.g. dial timeout. The connection lives until the
+// endpoint is closed and keeps only values of the context.
+func DialWebSocket(ctx context.Context, url string, options *websocket.DialOptions, opts ...ConnOpt) (*StreamEndpoint, error) {
+	conn, _, err := websocket.Dial(ctx, url, options)
+	if err != nil {
+		return nil, err
+	}
+	ctx = context.WithoutCancel(ctx)
+	return NewStreamEndpoint(ctx, NewWebSocketObjectStream(ctx, conn), opts...), nil
+}
+
+// WebSocketDialer returns dial function connecting to the websocket server, e.g. for DialReconnecting.
+// The context passed to the dial function bounds only the dial, the stream keeps only its values.
+func WebSocketDialer(url string, options *websocket.DialOptions) DialFunc {
+	return func(ctx context.Context) (ObjectStream, error) {
+		conn, _, err := websocket.Dial(ctx, url, options)
+		if err != nil {
+			return nil, err
+		}
+		return NewWebSocketObjectStream(context.WithoutCancel(ctx), conn), nil
+	}
+}
+
+// WebSocketHandler is http.Handler which upgrades requests to websocket connections
+// and serves jsonrpc over them. Every connection gets its own StreamEndpoint sharing
+// methods registered on the handler.
+type WebSocketHandler struct {
+	methods   RpcMethodRegistry
+	options   *websocket.AcceptOptions
+	readLimit int64
+	onConnect func(ctx context.Context, endpoint *StreamEndpoint)
+
+	logger *slog.Logger
+}
+
+func NewWebSocketHandler(options *websocket.AcceptOptions) *WebSocketHandler {
+	return &WebSocketHandler{
+		methods: NewMethodRegistry(),
+		options: options,
+		logger:  slog.Default(),
+	}
+}
+
+func (h *WebSocketHandler) GetMethods() RpcMethodRegistry {
+	return h.methods
+}
+
+func (h *WebSocketHandler) UseLogger(logger *slog.Logger) {
+	if logger == nil {
+		h.logger.Debug("ignored nil logger")
+		return
+	}
+	h.logger = logger
+}
+
+// UseReadLimit sets maximum size of a message read from accepted connections,
+// 32768 bytes by default, -1 disables the limit. Larger message closes the connection.
+func (h *WebSocketHandler) UseReadLimit(limit int64) {
+	h.readLimit = limit
+}
+
+// OnConnect sets callback invoked for every accepted connection before the endpoint
+// starts reading messages. Endpoint can be used to send requests and notifications to the peer.
+func (h *WebSocketHandler) OnConnect(onConnect func(ctx context.Context, endpoint *StreamEndpoint)) {
+	h.onConnect = onConnect
+}
+
+func (h *WebSocketHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	conn, err := websocket.Accept(w, r, h.options)
+	if err != nil {
+		h.logger.Debug("jsonrpc2: failed to accept websocket connection", "error", err)
+		return
+	}
+
+	ctx := r.Context()
+	endpoint := newStreamEndpoint(NewWebSocketObjectStream(ctx, conn))
+	endpoint.methodRegistry = h.methods
+	endpoint.UseLogger(h.logger)
+	if h.readLimit != 0 {
+		WithWebSocketReadLimit(h.readLimit)(endpoint)
+	}
+	if h.onConnect != nil {
+		h.onConnect(ctx, endpoint)
+	}
+	endpoint.start(ctx)
+	// connection is bound to the request context, so we have to wait until it is closed
+	<-endpoint.GetOnCloseListener()
+}
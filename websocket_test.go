@@ -0,0 +1,148 @@
+package jsonrpc2
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func createWebSocketServer(handler *WebSocketHandler) (*httptest.Server, string) {
+	srv := httptest.NewServer(handler)
+	return srv, "ws" + strings.TrimPrefix(srv.URL, "http")
+}
+
+func TestWebSocketRequest(t *testing.T) {
+	assert := assert.New(t)
+	handler := NewWebSocketHandler(nil)
+	RegisterEndpointMethod(handler, "hello", func(ctx context.Context, name string) (string, *Error) {
+		return "Hello " + name, nil
+	})
+	srv, url := createWebSocketServer(handler)
+	defer srv.Close()
+
+	c, err := DialWebSocket(context.Background(), url, nil)
+	assert.Nil(err)
+	defer c.Close()
+
+	r, err := Request[[]string, string](context.Background(), c, "hello", []string{"World"})
+	assert.Nil(err)
+	result, err := r.Unwrap()
+	assert.Nil(err)
+	assert.Equal("Hello World", result)
+}
+
+func TestWebSocketServerToClient(t *testing.T) {
+	assert := assert.New(t)
+	handler := NewWebSocketHandler(nil)
+	handler.OnConnect(func(ctx context.Context, endpoint *StreamEndpoint) {
+		go func() {
+			r, err := Request[[]string, string](ctx, endpoint, "whoami", []string{"server"})
+			if err != nil {
+				return
+			}
+			result, _ := r.Unwrap()
+			Notify(ctx, endpoint, "seen", []string{result})
+		}()
+	})
+	srv, url := createWebSocketServer(handler)
+	defer srv.Close()
+
+	signaled := make(chan string, 1)
+	c, err := DialWebSocket(context.Background(), url, nil, func(c *StreamEndpoint) {
+		RegisterEndpointMethod(c, "whoami", func(ctx context.Context, caller string) (string, *Error) {
+			return "client asked by " + caller, nil
+		})
+		RegisterEndpointMethod(c, "seen", func(ctx context.Context, data string) (interface{}, *Error) {
+			signaled <- data
+			return nil, nil
+		})
+	})
+	assert.Nil(err)
+	defer c.Close()
+
+	select {
+	case data := <-signaled:
+		assert.Equal("client asked by server", data)
+	case <-time.After(5 * time.Second):
+		assert.Fail("no notification from server")
+	}
+}
+
+func TestWebSocketClose(t *testing.T) {
+	assert := assert.New(t)
+	handler := NewWebSocketHandler(nil)
+	closed := make(chan struct{})
+	handler.OnConnect(func(ctx context.Context, endpoint *StreamEndpoint) {
+		go func() {
+			<-endpoint.GetOnCloseListener()
+			close(closed)
+		}()
+	})
+	srv, url := createWebSocketServer(handler)
+	defer srv.Close()
+
+	c, err := DialWebSocket(context.Background(), url, nil)
+	assert.Nil(err)
+	assert.Nil(c.Close())
+
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		assert.Fail("server endpoint not closed")
+	}
+}
+
+func TestWebSocketReadLimit(t *testing.T) {
+	assert := assert.New(t)
+	handler := NewWebSocketHandler(nil)
+	handler.UseReadLimit(1 << 20)
+	RegisterEndpointMethod(handler, "echo", func(ctx context.Context, data string) (string, *Error) {
+		return data, nil
+	})
+	srv, url := createWebSocketServer(handler)
+	defer srv.Close()
+
+	c, err := DialWebSocket(context.Background(), url, nil, WithWebSocketReadLimit(1<<20))
+	assert.Nil(err)
+	defer c.Close()
+
+	data := strings.Repeat("x", 100000)
+	response, err := Request[[]string, string](context.Background(), c, "echo", []string{data})
+	assert.Nil(err)
+	assert.Equal(data, response.Result)
+}
+
+func TestWebSocketDialTimeout(t *testing.T) {
+	assert := assert.New(t)
+	handler := NewWebSocketHandler(nil)
+	RegisterEndpointMethod(handler, "hello", func(ctx context.Context, name string) (string, *Error) {
+		return "Hello " + name, nil
+	})
+	srv, url := createWebSocketServer(handler)
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	c, err := DialWebSocket(ctx, url, nil)
+	cancel()
+	assert.Nil(err)
+	defer c.Close()
+
+	// connection outlives the dial context
+	response, err := Request[[]string, string](context.Background(), c, "hello", []string{"World"})
+	assert.Nil(err)
+	assert.Equal("Hello World", response.Result)
+
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	stream, err := WebSocketDialer(url, nil)(ctx)
+	cancel()
+	assert.Nil(err)
+	dialed := NewStreamEndpoint(context.Background(), stream)
+	defer dialed.Close()
+	response, err = Request[[]string, string](context.Background(), dialed, "hello", []string{"World"})
+	assert.Nil(err)
+	assert.Equal("Hello World", response.Result)
+}